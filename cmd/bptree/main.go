@@ -0,0 +1,175 @@
+// Command bptree inspects and edits an index file.
+//
+// Usage:
+//
+//	bptree [-file index.db] [-page-size size] <command> [arguments]
+//
+// The page size is read from the index file, -page-size overrides it or sets the page size of a new index file.
+//
+// The commands are:
+//
+//	info                      page size, page count, height and root page id
+//	dump-page <id>            decode and print a page
+//	scan [--from k] [--to k]  print the key value pairs from k (inclusive) to k (exclusive) in key order
+//	get <key>                 print the value of the key
+//	put <key> <value>         put the key value pair
+//	verify                    check the integrity of the tree
+package main
+
+import (
+	"b+tree/index"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "bptree:", err)
+		os.Exit(1)
+	}
+}
+
+func run(arguments []string, output io.Writer) error {
+	flags := flag.NewFlagSet("bptree", flag.ContinueOnError)
+	fileName := flags.String("file", "index.db", "index file")
+	pageSize := flags.Int("page-size", 0, "page size, read from the index file when not given")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("command is missing, one of info, dump-page, scan, get, put or verify")
+	}
+	command, commandArguments := flags.Arg(0), flags.Args()[1:]
+
+	tree, err := openTree(*fileName, *pageSize, command != "put")
+	if err != nil {
+		return err
+	}
+	err = runCommand(tree, command, commandArguments, output)
+	if closeErr := tree.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func runCommand(tree *index.BPlusTree, command string, arguments []string, output io.Writer) error {
+	switch command {
+	case "info":
+		return info(tree, output)
+	case "dump-page":
+		return dumpPage(tree, arguments, output)
+	case "scan":
+		return scan(tree, arguments, output)
+	case "get":
+		return get(tree, arguments, output)
+	case "put":
+		return put(tree, arguments)
+	case "verify":
+		return verify(tree, output)
+	}
+	return fmt.Errorf("unknown command %v", command)
+}
+
+func openTree(fileName string, pageSize int, readOnly bool) (*index.BPlusTree, error) {
+	options, err := index.StoredOptions(fileName)
+	if errors.Is(err, os.ErrNotExist) && !readOnly {
+		options, err = index.DefaultOptions(), nil
+		options.FileName = fileName
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pageSize != 0 {
+		options.PageSize = pageSize
+	}
+	options.ReadOnly = readOnly
+	return index.CreateBPlusTree(options)
+}
+
+func info(tree *index.BPlusTree, output io.Writer) error {
+	treeInfo, err := tree.Info()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(output, "page size:    %v\n", treeInfo.PageSize)
+	_, _ = fmt.Fprintf(output, "page count:   %v\n", treeInfo.PageCount)
+	_, _ = fmt.Fprintf(output, "height:       %v\n", treeInfo.Height)
+	_, _ = fmt.Fprintf(output, "root page id: %v\n", treeInfo.RootPageId)
+	return nil
+}
+
+func dumpPage(tree *index.BPlusTree, arguments []string, output io.Writer) error {
+	if len(arguments) != 1 {
+		return errors.New("usage: dump-page <id>")
+	}
+	pageId, err := strconv.Atoi(arguments[0])
+	if err != nil {
+		return fmt.Errorf("page id %v is not a number", arguments[0])
+	}
+	dump, err := tree.DumpPage(pageId)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(output, dump)
+	return nil
+}
+
+func scan(tree *index.BPlusTree, arguments []string, output io.Writer) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	from := flags.String("from", "", "first key, inclusive")
+	to := flags.String("to", "", "last key, exclusive")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	var start, end []byte
+	if *from != "" {
+		start = []byte(*from)
+	}
+	if *to != "" {
+		end = []byte(*to)
+	}
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	iterator := transaction.Scan(start, end)
+	for iterator.Next() {
+		_, _ = fmt.Fprintf(output, "%v\t%v\n", iterator.KeyValuePair().PrettyKey(), iterator.KeyValuePair().PrettyValue())
+	}
+	return iterator.Err()
+}
+
+func get(tree *index.BPlusTree, arguments []string, output io.Writer) error {
+	if len(arguments) != 1 {
+		return errors.New("usage: get <key>")
+	}
+	getResult := tree.Get([]byte(arguments[0]))
+	if getResult.Err != nil {
+		return getResult.Err
+	}
+	if !getResult.Found() {
+		return fmt.Errorf("key %v not found", arguments[0])
+	}
+	_, _ = fmt.Fprintln(output, getResult.KeyValuePair.PrettyValue())
+	return nil
+}
+
+func put(tree *index.BPlusTree, arguments []string) error {
+	if len(arguments) != 2 {
+		return errors.New("usage: put <key> <value>")
+	}
+	return tree.Put([]byte(arguments[0]), []byte(arguments[1]))
+}
+
+func verify(tree *index.BPlusTree, output io.Writer) error {
+	report := tree.Verify()
+	_, _ = fmt.Fprint(output, report)
+	if !report.Ok() {
+		return fmt.Errorf("%v problems found in the tree", len(report.Problems))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"b+tree/index"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func putKeys(t *testing.T, fileName string, keyValuePairs ...string) {
+	for index := 0; index < len(keyValuePairs); index = index + 2 {
+		if err := run([]string{"-file", fileName, "put", keyValuePairs[index], keyValuePairs[index+1]}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Failed while putting %v %v", keyValuePairs[index], err)
+		}
+	}
+}
+
+func TestPutsAndGetsAKey(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", fileName, "get", "A"}, output); err != nil {
+		t.Fatalf("Expected no error while getting A, received %v", err)
+	}
+	if output.String() != "Database\n" {
+		t.Fatalf("Expected value to be Database, received %v", output.String())
+	}
+}
+
+func TestFailsToGetAMissingKey(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	if err := run([]string{"-file", fileName, "get", "B"}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("Expected an error while getting a missing key")
+	}
+}
+
+func TestScansKeysInARange(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "C", "Systems", "A", "Database", "B", "Storage", "D", "OS")
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", fileName, "scan", "--from", "B", "--to", "D"}, output); err != nil {
+		t.Fatalf("Expected no error while scanning, received %v", err)
+	}
+	expected := "B\tStorage\nC\tSystems\n"
+	if output.String() != expected {
+		t.Fatalf("Expected scan output to be %q, received %q", expected, output.String())
+	}
+}
+
+func TestPrintsTheInfoOfAnIndexFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", fileName, "info"}, output); err != nil {
+		t.Fatalf("Expected no error while printing info, received %v", err)
+	}
+	if !strings.Contains(output.String(), "height:       1\n") || !strings.Contains(output.String(), "root page id: 1\n") {
+		t.Fatalf("Expected height 1 and root page id 1, received %v", output.String())
+	}
+}
+
+func TestDumpsAPage(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database", "B", "Storage")
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", fileName, "dump-page", "1"}, output); err != nil {
+		t.Fatalf("Expected no error while dumping a page, received %v", err)
+	}
+	if !strings.HasPrefix(output.String(), "page 1: leaf") || !strings.Contains(output.String(), "  B = Storage\n") {
+		t.Fatalf("Expected a dump of the leaf page 1, received %v", output.String())
+	}
+}
+
+func TestFailsToDumpTheMetaPage(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	if err := run([]string{"-file", fileName, "dump-page", "0"}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("Expected an error while dumping the meta page")
+	}
+}
+
+func TestVerifiesAnIndexFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database", "B", "Storage")
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", fileName, "verify"}, output); err != nil {
+		t.Fatalf("Expected no error while verifying, received %v", err)
+	}
+	if !strings.Contains(output.String(), "no problems found") {
+		t.Fatalf("Expected no problems, received %v", output.String())
+	}
+}
+
+func TestFailsGivenAnUnknownCommand(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	if err := run([]string{"-file", fileName, "delete"}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("Expected an error given an unknown command")
+	}
+}
+
+func TestPrintsTheInfoOfAnIndexFileUsingThePageSizeStoredInIt(t *testing.T) {
+	options := index.DefaultOptions()
+	options.FileName = filepath.Join(t.TempDir(), "index.db")
+	options.PageSize = 2 * os.Getpagesize()
+	tree, _ := index.CreateBPlusTree(options)
+	_ = tree.Put([]byte("A"), []byte("Database"))
+	_ = tree.Close()
+
+	output := &bytes.Buffer{}
+	if err := run([]string{"-file", options.FileName, "info"}, output); err != nil {
+		t.Fatalf("Expected no error while printing info, received %v", err)
+	}
+	if expected := fmt.Sprintf("page size:    %v\n", options.PageSize); !strings.Contains(output.String(), expected) {
+		t.Fatalf("Expected %q, received %v", expected, output.String())
+	}
+}
+
+func TestFailsGivenAPageSizeThatDoesNotMatchTheIndexFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.db")
+	putKeys(t, fileName, "A", "Database")
+
+	pageSize := fmt.Sprint(2 * os.Getpagesize())
+	if err := run([]string{"-file", fileName, "-page-size", pageSize, "get", "A"}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("Expected an error given a page size that does not match the index file")
+	}
+}
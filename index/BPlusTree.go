@@ -1,60 +1,291 @@
 package index
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
 type BPlusTree struct {
 	fileName      string
+	readOnly      bool
 	pagePool      *PagePool
 	pageHierarchy *PageHierarchy
 	freePageList  *FreePageList
+	metaPage      *MetaPage
+
+	// transactionLock is held for reading by the open read transactions and for writing by the mutators.
+	transactionLock *sync.RWMutex
 }
 
 const metaPageCount = 1
 const rootPageCount = 1
 
-func CreateBPlusTree(options Options) (*BPlusTree, error) {
-	indexFile, err := OpenIndexFile(options)
-	pagePool := NewPagePool(indexFile, options)
+var ErrReadOnly = errors.New("index is opened in read-only mode")
 
+func CreateBPlusTree(options Options) (*BPlusTree, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	storage, err := OpenStorage(options)
 	if err != nil {
 		return nil, err
 	}
+	pagePool := NewPagePool(storage, options)
 	tree := &BPlusTree{
-		fileName: options.FileName,
-		pagePool: pagePool,
+		fileName:        options.FileName,
+		readOnly:        options.ReadOnly,
+		pagePool:        pagePool,
+		transactionLock: &sync.RWMutex{},
 	}
-	if err := tree.create(options); err != nil {
+	existing := !pagePool.ContainsZeroPages()
+	options, err = tree.create(options)
+	if err != nil {
+		_ = pagePool.Close()
 		return nil, err
 	}
 	tree.pageHierarchy = NewPageHierarchy(pagePool, options.AllowedPageOccupancyPercentage, tree.freePageList)
+	tree.pageHierarchy.splitPolicy = options.SplitPolicy
+	if existing {
+		if err := tree.pageHierarchy.loadRootPage(tree.metaPage.rootPageId); err != nil {
+			_ = pagePool.Close()
+			return nil, err
+		}
+		if err := tree.pageHierarchy.rebuildFreePageList(); err != nil {
+			_ = pagePool.Close()
+			return nil, err
+		}
+	}
 	return tree, nil
 }
 
 func (tree BPlusTree) Put(key, value []byte) error {
+	if tree.readOnly {
+		return ErrReadOnly
+	}
+	tree.transactionLock.Lock()
+	defer tree.transactionLock.Unlock()
+
 	if err := tree.pageHierarchy.Put(KeyValuePair{key: append([]byte(nil), key...), value: append([]byte(nil), value...)}); err != nil {
 		return err
 	}
-	return nil
+	return tree.writeMetaPageIfChanged()
+}
+
+func (tree BPlusTree) PutIfAbsent(key, value []byte) (bool, error) {
+	return tree.Update(key, func(oldValue []byte, exists bool) ([]byte, bool) {
+		return value, !exists
+	})
+}
+
+func (tree BPlusTree) CompareAndSwap(key, oldValue, newValue []byte) (bool, error) {
+	return tree.Update(key, func(existingValue []byte, exists bool) ([]byte, bool) {
+		return newValue, exists && bytes.Equal(existingValue, oldValue)
+	})
+}
+
+// Update runs the update function against the current value of the key in a single descent of the tree.
+// The function receives the existing value (nil if the key does not exist) and returns the new value along with
+// a flag indicating whether the new value should be written. The existing value must not be retained or modified.
+func (tree BPlusTree) Update(key []byte, update UpdateFunc) (bool, error) {
+	if tree.readOnly {
+		return false, ErrReadOnly
+	}
+	tree.transactionLock.Lock()
+	defer tree.transactionLock.Unlock()
+
+	updated, err := tree.pageHierarchy.Update(append([]byte(nil), key...), func(oldValue []byte, exists bool) ([]byte, bool) {
+		value, ok := update(oldValue, exists)
+		if !ok {
+			return nil, false
+		}
+		return append([]byte(nil), value...), true
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := tree.writeMetaPageIfChanged(); err != nil {
+		return false, err
+	}
+	return updated, nil
 }
 
+// Get holds the read lock of the tree for the duration of the call. A goroutine with an open ReadTransaction reads
+// through the transaction instead, the read lock is not reentrant once a writer waits for it.
 func (tree BPlusTree) Get(key []byte) GetResult {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
 	return tree.pageHierarchy.Get(key)
 }
 
+// DeleteRange deletes all the keys greater than or equal to start and less than end, a nil end runs till the last key.
+func (tree BPlusTree) DeleteRange(start, end []byte) error {
+	if tree.readOnly {
+		return ErrReadOnly
+	}
+	tree.transactionLock.Lock()
+	defer tree.transactionLock.Unlock()
+
+	if err := tree.pageHierarchy.DeleteRange(start, end); err != nil {
+		return err
+	}
+	return tree.writeMetaPageIfChanged()
+}
+
+// Compact moves the live pages towards the start of the index file and truncates the free pages at its end.
+func (tree BPlusTree) Compact() error {
+	if tree.readOnly {
+		return ErrReadOnly
+	}
+	tree.transactionLock.Lock()
+	defer tree.transactionLock.Unlock()
+
+	if err := tree.pageHierarchy.Compact(); err != nil {
+		return err
+	}
+	return tree.writeMetaPageIfChanged()
+}
+
+// CopyTo rebuilds the tree into a new index file at path with tightly packed pages. The options may use a different
+// PageSize or AllowedPageOccupancyPercentage. The file is built next to path and renamed into place once complete,
+// path can not be the index file of the tree itself.
+func (tree BPlusTree) CopyTo(path string, options Options) error {
+	if options.InMemory {
+		return fmt.Errorf("CopyTo requires an index file, InMemory is not supported")
+	}
+	if isSameFile(path, tree.fileName) {
+		return fmt.Errorf("CopyTo can not copy the tree onto its own index file %v", path)
+	}
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	options.FileName = path + ".copy"
+	options.ReadOnly = false
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	_ = os.Remove(options.FileName)
+	storage, err := OpenStorage(options)
+	if err != nil {
+		return err
+	}
+	pagePool := NewPagePool(storage, options)
+	if err := tree.copyTo(pagePool, options); err != nil {
+		_ = pagePool.Close()
+		_ = os.Remove(options.FileName)
+		return err
+	}
+	if err := pagePool.Close(); err != nil {
+		return err
+	}
+	return os.Rename(options.FileName, path)
+}
+
+func isSameFile(path, otherPath string) bool {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	otherFileInfo, err := os.Stat(otherPath)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fileInfo, otherFileInfo)
+}
+
+// MultiGet returns the results in the same order as the keys, walking the tree once for all the keys.
+func (tree BPlusTree) MultiGet(keys [][]byte) []GetResult {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	return tree.pageHierarchy.MultiGet(keys)
+}
+
+func (tree *BPlusTree) Sync() error {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	return tree.pagePool.Sync()
+}
+
 func (tree *BPlusTree) Close() error {
+	tree.transactionLock.Lock()
+	defer tree.transactionLock.Unlock()
+
+	if !tree.readOnly {
+		if err := tree.pagePool.WriteMetaPage(tree.metaPage); err != nil {
+			return err
+		}
+	}
 	return tree.pagePool.Close()
 }
 
-func (tree *BPlusTree) create(options Options) error {
+func (tree BPlusTree) copyTo(pagePool *PagePool, options Options) error {
+	if _, err := pagePool.Allocate(metaPageCount); err != nil {
+		return err
+	}
+	builder := NewBottomUpBuilder(pagePool, options.AllowedPageOccupancyPercentage)
+	if err := tree.pageHierarchy.forEachKeyValuePair(tree.pageHierarchy.rootPage, builder.Add); err != nil {
+		return err
+	}
+	rootPageId, err := builder.Finish()
+	if err != nil {
+		return err
+	}
+	metaPage := NewMetaPage(options)
+	metaPage.rootPageId = rootPageId
+	if err := pagePool.WriteMetaPage(metaPage); err != nil {
+		return err
+	}
+	if err := pagePool.Truncate(pagePool.pageCount); err != nil {
+		return err
+	}
+	return pagePool.Sync()
+}
+
+func (tree *BPlusTree) create(options Options) (Options, error) {
 	if tree.pagePool.ContainsZeroPages() {
-		return tree.initialize(options)
+		return options, tree.initialize(options)
 	}
-	return nil
+	return tree.open(options)
+}
+
+func (tree *BPlusTree) open(options Options) (Options, error) {
+	metaPage, err := tree.pagePool.ReadMetaPage()
+	if err != nil {
+		return options, err
+	}
+	tree.metaPage = metaPage
+	tree.freePageList = &FreePageList{}
+	return metaPage.applyTo(options)
 }
 
 func (tree *BPlusTree) initialize(options Options) error {
+	tree.metaPage = NewMetaPage(options)
+	if options.ReadOnly {
+		tree.freePageList = InitializeFreePageList(metaPageCount+rootPageCount, 0)
+		return nil
+	}
 	_, err := tree.pagePool.Allocate(metaPageCount + rootPageCount + options.PreAllocatedPagePoolSize)
 	if err != nil {
 		return err
 	}
+	if err := tree.pagePool.WriteMetaPage(tree.metaPage); err != nil {
+		return err
+	}
 	tree.freePageList = InitializeFreePageList(metaPageCount+rootPageCount, options.PreAllocatedPagePoolSize)
 	return nil
 }
+
+// writeMetaPageIfChanged writes the meta page when the root page moved or pages were allocated or truncated, so
+// that a reopen neither loses the root page nor hands out the allocated pages again.
+func (tree BPlusTree) writeMetaPageIfChanged() error {
+	if tree.metaPage.rootPageId != tree.pageHierarchy.RootPageId() || tree.metaPage.pageCount != tree.pagePool.pageCount {
+		tree.metaPage.rootPageId = tree.pageHierarchy.RootPageId()
+		return tree.pagePool.WriteMetaPage(tree.metaPage)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package index
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"strconv"
 	"testing"
@@ -9,7 +11,7 @@ import (
 func TestPutsAndGets1000KeyValuePairsWithDefaultOptions(t *testing.T) {
 	options := DefaultOptions()
 	bPlusTree, _ := CreateBPlusTree(options)
-	defer deleteFile(bPlusTree.pagePool.indexFile)
+	defer deleteFile(bPlusTree.pagePool.storage)
 
 	for index := 1; index <= 1000; index++ {
 		err := bPlusTree.Put(
@@ -41,7 +43,7 @@ func TestPutsAndGets1000KeyValuePairsWithDefaultOptions(t *testing.T) {
 func TestPutsAndGets10000KeyValuePairsWithDefaultOptions(t *testing.T) {
 	options := DefaultOptions()
 	bPlusTree, _ := CreateBPlusTree(options)
-	defer deleteFile(bPlusTree.pagePool.indexFile)
+	defer deleteFile(bPlusTree.pagePool.storage)
 
 	for index := 1; index <= 10000; index++ {
 		err := bPlusTree.Put(
@@ -78,7 +80,7 @@ func TestPutsAndGets10000KeyValuePairsWithCustomOptionsToForceSplits(t *testing.
 		PreAllocatedPagePoolSize:       10,
 	}
 	bPlusTree, _ := CreateBPlusTree(options)
-	defer deleteFile(bPlusTree.pagePool.indexFile)
+	defer deleteFile(bPlusTree.pagePool.storage)
 
 	for index := 1; index <= 10000; index++ {
 		err := bPlusTree.Put(
@@ -106,3 +108,580 @@ func TestPutsAndGets10000KeyValuePairsWithCustomOptionsToForceSplits(t *testing.
 		}
 	}
 }
+
+func TestPutsIfAbsent10000KeyValuePairsWithCustomOptionsToForceSplits(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		put, err := bPlusTree.PutIfAbsent(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil || !put {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		put, _ := bPlusTree.PutIfAbsent([]byte("Key"+strconv.Itoa(index)), []byte("Updated"))
+		if put {
+			t.Fatalf("Expected key %v to be present", "Key"+strconv.Itoa(index))
+		}
+		getResult := bPlusTree.Get([]byte("Key" + strconv.Itoa(index)))
+		expected := KeyValuePair{
+			key:   []byte("Key" + strconv.Itoa(index)),
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestMultiGets10000KeyValuePairsWithCustomOptionsToForceSplits(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	var keys [][]byte
+	for index := 10000; index >= 1; index-- {
+		keys = append(keys, []byte("Key"+strconv.Itoa(index)))
+	}
+	getResults := bPlusTree.MultiGet(keys)
+	for index, getResult := range getResults {
+		expected := KeyValuePair{
+			key:   keys[index],
+			value: []byte("Value" + strconv.Itoa(10000-index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestDeletesARangeOf10000KeyValuePairsWithCustomOptionsToForceSplits(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	start, end := []byte("Key2"), []byte("Key7")
+	if err := bPlusTree.DeleteRange(start, end); err != nil {
+		t.Fatalf("Failed while deleting range %v", err)
+	}
+	if len(bPlusTree.freePageList.pageIds) == 0 {
+		t.Fatalf("Expected pages to be released to the free page list")
+	}
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := bPlusTree.Get(key)
+		deleted := bytes.Compare(key, start) >= 0 && bytes.Compare(key, end) < 0
+
+		if deleted && getResult.found {
+			t.Fatalf("Expected key %v to be deleted", string(key))
+		}
+		expected := KeyValuePair{key: key, value: []byte("Value" + strconv.Itoa(index))}
+		if !deleted && !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		expected := KeyValuePair{key: key, value: []byte("Value" + strconv.Itoa(index))}
+		if getResult := bPlusTree.Get(key); !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestGets10000KeyValuePairsAfterReopeningInReadOnlyMode(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	_ = bPlusTree.Close()
+
+	options.ReadOnly = true
+	readOnlyTree, err := CreateBPlusTree(options)
+	if err != nil {
+		t.Fatalf("Failed while opening in read-only mode %v", err)
+	}
+	defer func() { _ = readOnlyTree.Close() }()
+
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := readOnlyTree.Get(key)
+		expected := KeyValuePair{
+			key:   key,
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsWithFileIOStorage(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+		StorageType:                    FileIOStorage,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := bPlusTree.Get(
+			key,
+		)
+		expected := KeyValuePair{
+			key:   key,
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsInMemory(t *testing.T) {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+		InMemory:                       true,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer func() { _ = bPlusTree.Close() }()
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := bPlusTree.Get(
+			key,
+		)
+		expected := KeyValuePair{
+			key:   key,
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestCompactsTheIndexFileAfterDeletingARangeOf10000KeyValuePairs(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	start, end := []byte("Key1"), []byte("Key8")
+	_ = bPlusTree.DeleteRange(start, end)
+
+	sizeBeforeCompaction := bPlusTree.pagePool.storage.Size()
+	if err := bPlusTree.Compact(); err != nil {
+		t.Fatalf("Failed while compacting %v", err)
+	}
+	if bPlusTree.pagePool.storage.Size() >= sizeBeforeCompaction {
+		t.Fatalf("Expected file size to shrink from %v, received %v", sizeBeforeCompaction, bPlusTree.pagePool.storage.Size())
+	}
+	_ = bPlusTree.Close()
+
+	reopenedTree, _ := CreateBPlusTree(options)
+	defer func() { _ = reopenedTree.Close() }()
+
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := reopenedTree.Get(key)
+		deleted := bytes.Compare(key, start) >= 0 && bytes.Compare(key, end) < 0
+
+		if deleted && getResult.found {
+			t.Fatalf("Expected key %v to be deleted", string(key))
+		}
+		expected := KeyValuePair{key: key, value: []byte("Value" + strconv.Itoa(index))}
+		if !deleted && !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestCopies10000KeyValuePairsIntoADefragmentedIndexFile(t *testing.T) {
+	options := Options{
+		FileName:                       "./index.db",
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+	defer func() { _ = bPlusTree.Close() }()
+
+	for index := 1; index <= 10000; index++ {
+		err := bPlusTree.Put(
+			[]byte("Key"+strconv.Itoa(index)),
+			[]byte("Value"+strconv.Itoa(index)),
+		)
+		if err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	copyOptions := DefaultOptions()
+	copyOptions.PageSize = os.Getpagesize() * 2
+	copyOptions.AllowedPageOccupancyPercentage = 95
+	if err := bPlusTree.CopyTo("./copy.db", copyOptions); err != nil {
+		t.Fatalf("Failed while copying %v", err)
+	}
+
+	copyOptions.FileName = "./copy.db"
+	copiedTree, err := CreateBPlusTree(copyOptions)
+	if err != nil {
+		t.Fatalf("Failed while opening the copy %v", err)
+	}
+	defer deleteFile(copiedTree.pagePool.storage)
+	defer func() { _ = copiedTree.Close() }()
+
+	if copiedTree.pagePool.storage.Size() >= bPlusTree.pagePool.storage.Size() {
+		t.Fatalf("Expected the copy to be smaller than %v, received %v", bPlusTree.pagePool.storage.Size(), copiedTree.pagePool.storage.Size())
+	}
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := copiedTree.Get(key)
+		expected := KeyValuePair{
+			key:   key,
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+	for index := 10001; index <= 12000; index++ {
+		if err := copiedTree.Put([]byte("Key"+strconv.Itoa(index)), []byte("Value"+strconv.Itoa(index))); err != nil {
+			t.Fatalf("Failed while inserting into the copy %v", err)
+		}
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsWithKeyPrefixCompressionInFewerPages(t *testing.T) {
+	putAll := func(keyPrefixCompression bool) *BPlusTree {
+		options := Options{
+			PageSize:                       os.Getpagesize(),
+			AllowedPageOccupancyPercentage: 20,
+			PreAllocatedPagePoolSize:       10,
+			InMemory:                       true,
+			KeyPrefixCompression:           keyPrefixCompression,
+		}
+		bPlusTree, _ := CreateBPlusTree(options)
+		for index := 1; index <= 10000; index++ {
+			err := bPlusTree.Put(
+				[]byte("tenant/0001/path/Key"+strconv.Itoa(index)),
+				[]byte("Value"+strconv.Itoa(index)),
+			)
+			if err != nil {
+				t.Fatalf("Failed while inserting %v", err)
+			}
+		}
+		return bPlusTree
+	}
+	bPlusTree, uncompressedTree := putAll(true), putAll(false)
+	defer func() { _ = bPlusTree.Close() }()
+	defer func() { _ = uncompressedTree.Close() }()
+
+	for index := 1; index <= 10000; index++ {
+		key := []byte("tenant/0001/path/Key" + strconv.Itoa(index))
+		getResult := bPlusTree.Get(key)
+		expected := KeyValuePair{
+			key:   key,
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+	transaction := bPlusTree.BeginReadTransaction()
+	defer transaction.Close()
+
+	getResult := transaction.Get([]byte("tenant/0001/path/Key5000"))
+	if getResult.KeyValuePair.PrettyValue() != "Value5000" {
+		t.Fatalf("Expected value Value5000 in the read transaction, received %v", getResult.KeyValuePair.PrettyValue())
+	}
+	if bPlusTree.pagePool.pageCount >= uncompressedTree.pagePool.pageCount {
+		t.Fatalf("Expected fewer than %v pages with key prefix compression, received %v", uncompressedTree.pagePool.pageCount, bPlusTree.pagePool.pageCount)
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsWithLongKeysUsingTruncatedSeparators(t *testing.T) {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		AllowedPageOccupancyPercentage: 20,
+		PreAllocatedPagePoolSize:       10,
+		InMemory:                       true,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer func() { _ = bPlusTree.Close() }()
+
+	keyOf := func(index int) []byte {
+		return []byte("https://example.com/tenants/0001/documents/" + strconv.Itoa(index) + "/revisions/latest")
+	}
+	for index := 1; index <= 10000; index++ {
+		if err := bPlusTree.Put(keyOf(index), []byte("Value"+strconv.Itoa(index))); err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	for index := 1; index <= 10000; index++ {
+		getResult := bPlusTree.Get(keyOf(index))
+		expected := KeyValuePair{
+			key:   keyOf(index),
+			value: []byte("Value" + strconv.Itoa(index)),
+		}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+	for _, separator := range bPlusTree.pageHierarchy.rootPage.AllKeyValuePairs() {
+		if len(separator.key) >= len(keyOf(1)) {
+			t.Fatalf("Expected separator %v to be shorter than the keys", separator.PrettyKey())
+		}
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsWithCompressionAfterReopening(t *testing.T) {
+	for _, compression := range []Compression{FlateCompression, LZCompression} {
+		options := Options{
+			PageSize:                       os.Getpagesize(),
+			FileName:                       "./index.db",
+			AllowedPageOccupancyPercentage: 20,
+			PreAllocatedPagePoolSize:       10,
+			Compression:                    compression,
+		}
+		valueOf := func(index int) []byte {
+			return []byte(`{"id":` + strconv.Itoa(index) + `,"tenant":"0001","status":"active","tags":["index","storage"]}`)
+		}
+		bPlusTree, _ := CreateBPlusTree(options)
+		for index := 1; index <= 10000; index++ {
+			if err := bPlusTree.Put([]byte("Key"+strconv.Itoa(index)), valueOf(index)); err != nil {
+				t.Fatalf("Failed while inserting %v", err)
+			}
+		}
+		_ = bPlusTree.Close()
+
+		bPlusTree, err := CreateBPlusTree(options)
+		if err != nil {
+			t.Fatalf("Failed while reopening %v", err)
+		}
+		for index := 1; index <= 10000; index++ {
+			key := []byte("Key" + strconv.Itoa(index))
+			getResult := bPlusTree.Get(key)
+			expected := KeyValuePair{key: key, value: valueOf(index)}
+			if !expected.Equals(getResult.KeyValuePair) {
+				t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+			}
+		}
+		transaction := bPlusTree.BeginReadTransaction()
+		if getResult := transaction.Get([]byte("Key5000")); !bytes.Equal(valueOf(5000), getResult.KeyValuePair.value) {
+			t.Fatalf("Expected value %v in the read transaction, received %v", string(valueOf(5000)), getResult.KeyValuePair.PrettyValue())
+		}
+		transaction.Close()
+		_ = bPlusTree.Close()
+		deleteFile(bPlusTree.pagePool.storage)
+	}
+}
+
+func TestPutsAndGets10000KeyValuePairsWithEncryptionAfterReopening(t *testing.T) {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./index.db",
+		AllowedPageOccupancyPercentage: 30,
+		PreAllocatedPagePoolSize:       10,
+		KeyProvider:                    newKeyRing(),
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	for index := 1; index <= 10000; index++ {
+		if err := bPlusTree.Put([]byte("Key"+strconv.Itoa(index)), []byte("Value"+strconv.Itoa(index))); err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	_ = bPlusTree.Close()
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	withoutKeyProvider := options
+	withoutKeyProvider.KeyProvider = nil
+	if _, err := CreateBPlusTree(withoutKeyProvider); err == nil {
+		t.Fatalf("Expected an error while reopening an encrypted index file without a KeyProvider")
+	}
+
+	bPlusTree, err := CreateBPlusTree(options)
+	if err != nil {
+		t.Fatalf("Failed while reopening %v", err)
+	}
+	defer func() { _ = bPlusTree.Close() }()
+	for index := 1; index <= 10000; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		getResult := bPlusTree.Get(key)
+		expected := KeyValuePair{key: key, value: []byte("Value" + strconv.Itoa(index))}
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
+
+func TestPutsAndGets5000KeyValuePairsOfMixedSizesWithEachSplitPolicy(t *testing.T) {
+	valueOf := func(index int) []byte {
+		if index%7 == 0 {
+			return bytes.Repeat([]byte(strconv.Itoa(index)), 150)
+		}
+		return []byte("Value" + strconv.Itoa(index))
+	}
+	for _, splitPolicy := range []SplitPolicy{ByteBalancedSplit, CountBalancedSplit} {
+		options := Options{
+			PageSize:                       os.Getpagesize(),
+			FileName:                       "./index.db",
+			AllowedPageOccupancyPercentage: 80,
+			PreAllocatedPagePoolSize:       10,
+			SplitPolicy:                    splitPolicy,
+		}
+		bPlusTree, _ := CreateBPlusTree(options)
+		for index := 1; index <= 5000; index++ {
+			if err := bPlusTree.Put([]byte("Key"+strconv.Itoa(index)), valueOf(index)); err != nil {
+				t.Fatalf("Failed while inserting %v", err)
+			}
+		}
+		for index := 1; index <= 5000; index++ {
+			key := []byte("Key" + strconv.Itoa(index))
+			getResult := bPlusTree.Get(key)
+			expected := KeyValuePair{key: key, value: valueOf(index)}
+			if !expected.Equals(getResult.KeyValuePair) {
+				t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+			}
+		}
+		_ = bPlusTree.Close()
+		deleteFile(bPlusTree.pagePool.storage)
+	}
+}
+
+func TestLeavesFullLeafPagesBehindAfterPutting10000IncreasingKeys(t *testing.T) {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./index.db",
+		AllowedPageOccupancyPercentage: 80,
+		PreAllocatedPagePoolSize:       10,
+	}
+	bPlusTree, _ := CreateBPlusTree(options)
+	defer deleteFile(bPlusTree.pagePool.storage)
+
+	for index := 1; index <= 10000; index++ {
+		key := []byte(fmt.Sprintf("Key%05d", index))
+		if err := bPlusTree.Put(key, []byte("Value"+strconv.Itoa(index))); err != nil {
+			t.Fatalf("Failed while inserting %v", err)
+		}
+	}
+	var leafPages []*Page
+	var collectLeafPages func(page *Page)
+	collectLeafPages = func(page *Page) {
+		if page.isLeaf() {
+			leafPages = append(leafPages, page)
+			return
+		}
+		for _, childPageId := range page.childPageIds {
+			childPage, _ := bPlusTree.pageHierarchy.fetchOrCachePage(childPageId)
+			collectLeafPages(childPage)
+		}
+	}
+	collectLeafPages(bPlusTree.pageHierarchy.rootPage)
+
+	minimumSize := options.PageSize * 70 / 100
+	for _, leafPage := range leafPages[:len(leafPages)-1] {
+		if leafPage.size() < minimumSize {
+			t.Fatalf("Expected leaf page %v to be at least %v bytes, received %v", leafPage.id, minimumSize, leafPage.size())
+		}
+	}
+}
@@ -1,19 +1,24 @@
 package index
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
 )
 
 func TestCreatesABPlusTreeByPreAllocatingPagesAlongWithMetaPageAndRootPage(t *testing.T) {
 	options := Options{
-		PageSize:                 os.Getpagesize(),
-		FileName:                 "./test",
-		PreAllocatedPagePoolSize: 6,
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./test",
+		PreAllocatedPagePoolSize:       6,
+		AllowedPageOccupancyPercentage: 80,
 	}
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	expectedPageCount := options.PreAllocatedPagePoolSize + metaPageCount + rootPageCount
 	actualPageCount := tree.pagePool.pageCount
@@ -25,12 +30,13 @@ func TestCreatesABPlusTreeByPreAllocatingPagesAlongWithMetaPageAndRootPage(t *te
 
 func TestCreatesABPlusTreeWithFreePageList(t *testing.T) {
 	options := Options{
-		PageSize:                 os.Getpagesize(),
-		FileName:                 "./test",
-		PreAllocatedPagePoolSize: 6,
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./test",
+		PreAllocatedPagePoolSize:       6,
+		AllowedPageOccupancyPercentage: 80,
 	}
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	expected := []int{2, 3, 4, 5, 6, 7} //first 2 pages for meta and root
 	freePageIds := tree.freePageList.pageIds
@@ -43,7 +49,7 @@ func TestCreatesABPlusTreeWithFreePageList(t *testing.T) {
 func TestCreatesABPlusTreeWithARootPage(t *testing.T) {
 	options := DefaultOptions()
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	if tree.pageHierarchy.rootPage == nil {
 		t.Fatalf("Expected root page to be non-nil received nil")
@@ -53,7 +59,7 @@ func TestCreatesABPlusTreeWithARootPage(t *testing.T) {
 func TestCreatesABPlusTreeByCachingRootPage(t *testing.T) {
 	options := DefaultOptions()
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	rootPageId := tree.pageHierarchy.RootPageId()
 	rootPage := tree.pageHierarchy.PageById(rootPageId)
@@ -66,7 +72,7 @@ func TestCreatesABPlusTreeByCachingRootPage(t *testing.T) {
 func TestDoesNotGetByKeyAsSearchedKeyDoesNotExist(t *testing.T) {
 	options := DefaultOptions()
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	tree.pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{key: []byte("A")},
@@ -111,18 +117,20 @@ func TestGetsByKeyGivenKeyIsFoundInTheNonLeafPage(t *testing.T) {
 	}
 
 	options := Options{
-		PageSize:                 os.Getpagesize(),
-		FileName:                 "./test",
-		PreAllocatedPagePoolSize: 8,
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./test",
+		PreAllocatedPagePoolSize:       8,
+		AllowedPageOccupancyPercentage: 80,
 	}
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	tree.pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	writeLeftPageToFile(options.FileName, options.PageSize)
 	writeRightPageToFile(options.FileName, options.PageSize)
 	tree.pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	tree.pageHierarchy.rootPage.level = 1
 
 	expectedKeyValuePair := KeyValuePair{
 		key:   []byte("B"),
@@ -138,7 +146,7 @@ func TestGetsByKeyGivenKeyIsFoundInTheNonLeafPage(t *testing.T) {
 func TestPutsAKeyValuePair(t *testing.T) {
 	options := DefaultOptions()
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	tree.pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -160,15 +168,541 @@ func TestPutsAKeyValuePair(t *testing.T) {
 
 func TestClosesBPlusTree(t *testing.T) {
 	options := Options{
-		PageSize:                 os.Getpagesize(),
-		FileName:                 "./test",
-		PreAllocatedPagePoolSize: 6,
+		PageSize:                       os.Getpagesize(),
+		FileName:                       "./test",
+		PreAllocatedPagePoolSize:       6,
+		AllowedPageOccupancyPercentage: 80,
 	}
 	tree, _ := CreateBPlusTree(options)
-	defer deleteFile(tree.pagePool.indexFile)
+	defer deleteFile(tree.pagePool.storage)
 
 	err := tree.Close()
 	if err != nil {
 		t.Fatalf("Expected no error while closing the BPlusTree file, but received %v", err)
 	}
 }
+
+func TestPutsAKeyValuePairGivenKeyIsAbsent(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	put, _ := tree.PutIfAbsent([]byte("A"), []byte("Storage"))
+	if put != true {
+		t.Fatalf("Expected key value pair to be put given key is absent")
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Storage")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestDoesNotPutAKeyValuePairGivenKeyIsPresent(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	put, _ := tree.PutIfAbsent([]byte("A"), []byte("Database"))
+	if put != false {
+		t.Fatalf("Expected key value pair not to be put given key is present")
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Storage")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestSwapsTheValueGivenExistingValueMatches(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	swapped, _ := tree.CompareAndSwap([]byte("A"), []byte("Storage"), []byte("Database"))
+	if swapped != true {
+		t.Fatalf("Expected value to be swapped given existing value matches")
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Database")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestDoesNotSwapTheValueGivenExistingValueDoesNotMatch(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	swapped, _ := tree.CompareAndSwap([]byte("A"), []byte("Systems"), []byte("Database"))
+	if swapped != false {
+		t.Fatalf("Expected value not to be swapped given existing value does not match")
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Storage")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestDoesNotSwapTheValueGivenKeyIsAbsent(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	swapped, _ := tree.CompareAndSwap([]byte("A"), nil, []byte("Database"))
+	if swapped != false {
+		t.Fatalf("Expected value not to be swapped given key is absent")
+	}
+	getResult := tree.Get([]byte("A"))
+	if getResult.found != false {
+		t.Fatalf("Expected key to be absent")
+	}
+}
+
+func TestUpdatesTheValueUsingTheExistingValue(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	updated, _ := tree.Update([]byte("A"), func(oldValue []byte, exists bool) ([]byte, bool) {
+		return append(append([]byte(nil), oldValue...), []byte(" Engine")...), exists
+	})
+	if updated != true {
+		t.Fatalf("Expected value to be updated")
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Storage Engine")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestGetsMultipleKeysInTheOrderOfKeys(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	_ = tree.Put([]byte("B"), []byte("Database"))
+	_ = tree.Put([]byte("C"), []byte("Systems"))
+
+	getResults := tree.MultiGet([][]byte{[]byte("C"), []byte("D"), []byte("A")})
+	expected := []KeyValuePair{
+		{key: []byte("C"), value: []byte("Systems")},
+		{},
+		{key: []byte("A"), value: []byte("Storage")},
+	}
+
+	for index, getResult := range getResults {
+		if !expected[index].Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected KeyValuePair at %v to be %v, received %v", index, expected[index], getResult.KeyValuePair)
+		}
+	}
+	if getResults[1].found != false {
+		t.Fatalf("Expected key D to be missing")
+	}
+}
+
+func TestDeletesARangeOfKeys(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	_ = tree.Put([]byte("B"), []byte("Database"))
+	_ = tree.Put([]byte("C"), []byte("Systems"))
+	_ = tree.Put([]byte("D"), []byte("OS"))
+
+	_ = tree.DeleteRange([]byte("B"), []byte("D"))
+
+	expected := []KeyValuePair{
+		{key: []byte("A"), value: []byte("Storage")},
+		{key: []byte("D"), value: []byte("OS")},
+	}
+	pageKeyValuePairs := tree.pageHierarchy.rootPage.AllKeyValuePairs()
+	if !reflect.DeepEqual(expected, pageKeyValuePairs) {
+		t.Fatalf("Expected Key value pairs to be %v, received %v", expected, pageKeyValuePairs)
+	}
+}
+
+func TestDeletesTheKeysTillTheLastKeyGivenTheEndOfTheRangeIsNil(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+	_ = tree.Put([]byte("B"), []byte("Database"))
+	_ = tree.Put([]byte("C"), []byte("Systems"))
+
+	_ = tree.DeleteRange([]byte("B"), nil)
+
+	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Storage")}}
+	pageKeyValuePairs := tree.pageHierarchy.rootPage.AllKeyValuePairs()
+	if !reflect.DeepEqual(expected, pageKeyValuePairs) {
+		t.Fatalf("Expected Key value pairs to be %v, received %v", expected, pageKeyValuePairs)
+	}
+}
+
+func TestDeletesTheKeysTillTheLastKeyAcrossPagesGivenTheEndOfTheRangeIsNil(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(5000)
+	defer func() { _ = tree.Close() }()
+
+	_ = tree.DeleteRange([]byte("Key2"), nil)
+
+	report := tree.Verify()
+	if !report.Ok() || report.KeyCount != 1112 {
+		t.Fatalf("Expected 1112 keys without problems, received %v", report)
+	}
+	if tree.Get([]byte("Key4999")).Found() || !tree.Get([]byte("Key1999")).Found() {
+		t.Fatalf("Expected only the keys before Key2 to be found")
+	}
+}
+
+func TestDeletesAllTheKeysAcrossPagesGivenTheRangeIsUnbounded(t *testing.T) {
+	for _, start := range [][]byte{nil, {}} {
+		tree := createInMemoryTreeWithKeys(5000)
+
+		if err := tree.DeleteRange(start, nil); err != nil {
+			t.Fatalf("Expected no error while deleting all the keys, received %v", err)
+		}
+		report := tree.Verify()
+		if !report.Ok() || report.KeyCount != 0 || report.Height != 1 {
+			t.Fatalf("Expected no keys in a tree of height 1 without problems, received %v", report)
+		}
+		_ = tree.Put([]byte("Key1"), []byte("Value1"))
+		if !tree.Get([]byte("Key1")).Found() {
+			t.Fatalf("Expected the key put after deleting all the keys to be found")
+		}
+		_ = tree.Close()
+	}
+}
+
+func TestDoesNotCreateABPlusTreeGivenOptionsAreInvalid(t *testing.T) {
+	options := DefaultOptions()
+	options.PreAllocatedPagePoolSize = 0
+
+	_, err := CreateBPlusTree(options)
+	if err == nil {
+		t.Fatalf("Expected an error while creating a BPlusTree with invalid options")
+	}
+}
+
+func TestDoesNotOpenABPlusTreeGivenPageSizeDoesNotMatchTheIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	_ = tree.Close()
+
+	options.PageSize = options.PageSize * 2
+	_, err := CreateBPlusTree(options)
+	if err == nil {
+		t.Fatalf("Expected an error while opening a BPlusTree with a different PageSize")
+	}
+}
+
+func TestOpensABPlusTreeWithAllowedPageOccupancyPercentageFromTheIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	_ = tree.Close()
+
+	reopenOptions := DefaultOptions()
+	reopenOptions.AllowedPageOccupancyPercentage = 50
+	reopenedTree, _ := CreateBPlusTree(reopenOptions)
+	defer func() { _ = reopenedTree.Close() }()
+
+	allowedPageOccupancyPercentage := reopenedTree.pageHierarchy.allowedPageOccupancyPercentage
+	if allowedPageOccupancyPercentage != options.AllowedPageOccupancyPercentage {
+		t.Fatalf("Expected AllowedPageOccupancyPercentage to be %v, received %v", options.AllowedPageOccupancyPercentage, allowedPageOccupancyPercentage)
+	}
+}
+
+func TestDoesNotPutAKeyValuePairInAReadOnlyBPlusTree(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	_ = tree.Close()
+
+	options.ReadOnly = true
+	readOnlyTree, _ := CreateBPlusTree(options)
+	defer func() { _ = readOnlyTree.Close() }()
+
+	err := readOnlyTree.Put([]byte("A"), []byte("Storage"))
+	if err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly while putting in a read-only BPlusTree, received %v", err)
+	}
+	err = readOnlyTree.DeleteRange([]byte("A"), []byte("B"))
+	if err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly while deleting from a read-only BPlusTree, received %v", err)
+	}
+}
+
+func TestCreatesAnInMemoryBPlusTreeWithoutAnIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+
+	if _, err := os.Stat(options.FileName); !os.IsNotExist(err) {
+		t.Fatalf("Expected no index file for an in-memory BPlusTree, received %v", err)
+	}
+	getResult := tree.Get([]byte("A"))
+	expected := KeyValuePair{key: []byte("A"), value: []byte("Storage")}
+
+	if !expected.Equals(getResult.KeyValuePair) {
+		t.Fatalf("Expected KeyValuePair to be %v, received %v", expected, getResult.KeyValuePair)
+	}
+}
+
+func TestRestoresTheAllocatedPageCountWhileReopeningABPlusTree(t *testing.T) {
+	options := DefaultOptions()
+	options.PreAllocatedPagePoolSize = 2
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	for count := 1; count <= 200; count++ {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%03d", count)), make([]byte, 100))
+	}
+	pageCount, fileSize := tree.pagePool.pageCount, tree.pagePool.storage.Size()
+	_ = tree.Close()
+
+	for reopen := 1; reopen <= 3; reopen++ {
+		reopenedTree, _ := CreateBPlusTree(options)
+		if reopenedTree.pagePool.pageCount != pageCount {
+			t.Fatalf("Expected %v page count after reopening, received %v", pageCount, reopenedTree.pagePool.pageCount)
+		}
+		_ = reopenedTree.Put([]byte(fmt.Sprintf("Key%03d", reopen)), []byte("Updated"))
+		_ = reopenedTree.Close()
+	}
+	reopenedTree, _ := CreateBPlusTree(options)
+	defer func() { _ = reopenedTree.Close() }()
+	if reopenedTree.pagePool.storage.Size() != fileSize {
+		t.Fatalf("Expected the file size to stay %v after reopening, received %v", fileSize, reopenedTree.pagePool.storage.Size())
+	}
+}
+
+func TestRebuildsTheFreePageListWhileReopeningABPlusTree(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	for count := 1; count <= 500; count++ {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%03d", count)), make([]byte, 100))
+	}
+	_ = tree.DeleteRange([]byte("Key100"), []byte("Key400"))
+	freePageIds := append([]int(nil), tree.freePageList.pageIds...)
+	_ = tree.Close()
+
+	reopenedTree, _ := CreateBPlusTree(options)
+	defer func() { _ = reopenedTree.Close() }()
+
+	if len(freePageIds) == 0 || !reflect.DeepEqual(reopenedTree.freePageList.pageIds, freePageIds) {
+		t.Fatalf("Expected free page ids %v after reopening, received %v", freePageIds, reopenedTree.freePageList.pageIds)
+	}
+	report := reopenedTree.Verify()
+	if !report.Ok() || report.FreePageCount != len(freePageIds) {
+		t.Fatalf("Expected no problems and %v free pages, received %v", len(freePageIds), report)
+	}
+}
+
+func TestPutsKeyValuePairsThatFitInAPageOnlyAfterTheSplit(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+
+	_ = tree.Put([]byte("A"), make([]byte, options.PageSize/2))
+	if err := tree.Put([]byte("B"), make([]byte, options.PageSize*5/8)); err != nil {
+		t.Fatalf("Expected no error while putting a key value pair next to a large one, received %v", err)
+	}
+	if !tree.Get([]byte("A")).Found() || !tree.Get([]byte("B")).Found() {
+		t.Fatalf("Expected both the keys to be found")
+	}
+	if report := tree.Verify(); !report.Ok() {
+		t.Fatalf("Expected no problems, received %v", report)
+	}
+}
+
+func TestDiscardsAKeyValuePairThatCouldNotBeWritten(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+
+	_ = tree.Put([]byte("A"), make([]byte, options.PageSize/10))
+	_ = tree.Put([]byte("C"), make([]byte, options.PageSize*2/3))
+	err := tree.Put([]byte("B"), make([]byte, options.PageSize*19/20))
+	if err == nil {
+		t.Fatalf("Expected an error while putting a key value pair that leaves no split fitting in a page")
+	}
+	if tree.Get([]byte("B")).Found() || !tree.Get([]byte("A")).Found() || !tree.Get([]byte("C")).Found() {
+		t.Fatalf("Expected only the keys put before the failure to be found")
+	}
+	if report := tree.Verify(); !report.Ok() || report.KeyCount != 2 {
+		t.Fatalf("Expected 2 keys without problems, received %v", report)
+	}
+}
+
+func TestDoesNotPutAKeyValuePairLargerThanAPage(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+
+	if err := tree.Put([]byte("A"), make([]byte, options.PageSize)); err == nil {
+		t.Fatalf("Expected an error while putting a key value pair larger than a page")
+	}
+	if tree.Get([]byte("A")).Found() {
+		t.Fatalf("Expected the key not to be found")
+	}
+}
+
+func TestDoesNotPutAKeyLargerThanTheMaximumKeySize(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+
+	if err := tree.Put(make([]byte, options.PageSize/4), []byte("A")); !errors.Is(err, ErrKeyTooLarge) {
+		t.Fatalf("Expected ErrKeyTooLarge while putting a key larger than the maximum key size, received %v", err)
+	}
+	if report := tree.Verify(); !report.Ok() || report.KeyCount != 0 {
+		t.Fatalf("Expected no keys without problems, received %v", report)
+	}
+}
+
+func TestPutsKeysOfTheMaximumKeySizeAcrossSplitsOfNonLeafPages(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	// the neighbouring keys differ only in the last bytes, so the separators in the non-leaf pages are as long as the keys
+	random := rand.New(rand.NewSource(1))
+	keys := make([][]byte, 2000)
+	for index := range keys {
+		keys[index] = make([]byte, tree.pageHierarchy.maxKeySize())
+		keys[index][0] = byte(random.Intn(256))
+		random.Read(keys[index][len(keys[index])-4:])
+		if err := tree.Put(keys[index], []byte("A")); err != nil {
+			t.Fatalf("Expected no error while putting the key at %v, received %v", index, err)
+		}
+	}
+	_ = tree.Close()
+
+	reopenedTree, _ := CreateBPlusTree(options)
+	defer func() { _ = reopenedTree.Close() }()
+	report := reopenedTree.Verify()
+	if !report.Ok() || report.KeyCount != len(keys) {
+		t.Fatalf("Expected %v keys without problems, received %v", len(keys), report)
+	}
+	for index, key := range keys {
+		if !reopenedTree.Get(key).Found() {
+			t.Fatalf("Expected the key at %v to be found", index)
+		}
+	}
+}
+
+func TestDoesNotCopyABPlusTreeOntoItsOwnIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	_ = tree.Put([]byte("A"), []byte("Storage"))
+
+	if err := tree.CopyTo("./"+options.FileName, options); err == nil {
+		t.Fatalf("Expected an error while copying a BPlusTree onto its own index file")
+	}
+	if !tree.Get([]byte("A")).Found() {
+		t.Fatalf("Expected the key to be found after the failed copy")
+	}
+}
+
+func TestCopiesABPlusTreeWithoutCachingItsPages(t *testing.T) {
+	options := DefaultOptions()
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	for count := 1; count <= 500; count++ {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%03d", count)), make([]byte, 100))
+	}
+	tree.pageHierarchy.pageById = map[int]*Page{tree.pageHierarchy.rootPage.id: tree.pageHierarchy.rootPage}
+
+	copyOptions := DefaultOptions()
+	if err := tree.CopyTo("./copy.db", copyOptions); err != nil {
+		t.Fatalf("Expected no error while copying, received %v", err)
+	}
+	defer func() { _ = os.Remove("./copy.db") }()
+
+	if len(tree.pageHierarchy.pageById) != 1 {
+		t.Fatalf("Expected only the root page to be cached, received %v cached pages", len(tree.pageHierarchy.pageById))
+	}
+}
+
+func TestGetsKeysWhilePuttingKeysConcurrently(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+	for count := 1; count <= 500; count++ {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%04d", count)), []byte("Value"))
+	}
+	tree.pageHierarchy.pageById = map[int]*Page{tree.pageHierarchy.rootPage.id: tree.pageHierarchy.rootPage}
+
+	done := make(chan struct{})
+	waitGroup := &sync.WaitGroup{}
+	for reader := 0; reader < 4; reader++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for count := 1; ; count = count%500 + 1 {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				key := []byte(fmt.Sprintf("Key%04d", count))
+				if !tree.Get(key).Found() || !tree.MultiGet([][]byte{key})[0].Found() {
+					t.Errorf("Expected %v to be found", string(key))
+					return
+				}
+			}
+		}()
+	}
+	for count := 501; count <= 2000; count++ {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%04d", count)), []byte("Value"))
+	}
+	close(done)
+	waitGroup.Wait()
+}
+
+func TestPutsKeysInDescendingOrderAcrossSplitsOfNonLeafPages(t *testing.T) {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		PreAllocatedPagePoolSize:       8,
+		AllowedPageOccupancyPercentage: 20,
+		InMemory:                       true,
+	}
+	tree, _ := CreateBPlusTree(options)
+	defer func() { _ = tree.Close() }()
+	for count := 20000; count > 0; count-- {
+		_ = tree.Put([]byte(fmt.Sprintf("Key%05d", count)), []byte("Value"))
+	}
+
+	report := tree.Verify()
+	if !report.Ok() || report.Height < 3 || report.KeyCount != 20000 {
+		t.Fatalf("Expected 20000 keys in a tree of height 3 or more without problems, received %v", report)
+	}
+	for count := 1; count <= 20000; count = count + 97 {
+		if !tree.Get([]byte(fmt.Sprintf("Key%05d", count))).Found() {
+			t.Fatalf("Expected Key%05d to be found", count)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package index
+
+// BottomUpBuilder builds a B+Tree from key value pairs that arrive in key order. Leaf pages are packed till the
+// allowed page occupancy, written once and never revisited, then the non-leaf levels are built over them.
+type BottomUpBuilder struct {
+	pagePool        *PagePool
+	allowedPageSize int
+	currentPage     *Page
+	leafPages       []builtPage
+}
+
+type builtPage struct {
+	id       int
+	firstKey []byte
+}
+
+func NewBottomUpBuilder(pagePool *PagePool, allowedPageOccupancyPercentage int) *BottomUpBuilder {
+	return &BottomUpBuilder{
+		pagePool:        pagePool,
+		allowedPageSize: allowedPageOccupancyPercentage * pagePool.pageSize / 100,
+		currentPage:     &Page{compressKeyPrefix: pagePool.keyPrefixCompression},
+	}
+}
+
+func (builder *BottomUpBuilder) Add(keyValuePair KeyValuePair) error {
+	page := builder.currentPage
+	page.insertAt(len(page.keyValuePairs), keyValuePair)
+	if len(page.keyValuePairs) > 1 && page.size() > builder.allowedPageSize {
+		page.deleteRange(len(page.keyValuePairs)-1, len(page.keyValuePairs))
+		leafPage, err := builder.write(page, page.keyValuePairs[0].key)
+		if err != nil {
+			return err
+		}
+		builder.leafPages = append(builder.leafPages, leafPage)
+		builder.currentPage = &Page{keyValuePairs: []KeyValuePair{keyValuePair}, compressKeyPrefix: builder.pagePool.keyPrefixCompression}
+	}
+	return nil
+}
+
+// Finish writes the last leaf page along with all the non-leaf levels and returns the id of the root page.
+func (builder *BottomUpBuilder) Finish() (int, error) {
+	var firstKey []byte
+	if len(builder.currentPage.keyValuePairs) > 0 {
+		firstKey = builder.currentPage.keyValuePairs[0].key
+	}
+	leafPage, err := builder.write(builder.currentPage, firstKey)
+	if err != nil {
+		return 0, err
+	}
+	pages := append(builder.leafPages, leafPage)
+	for level := 1; len(pages) > 1; level++ {
+		pages, err = builder.buildNonLeafLevel(pages, level)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return pages[0].id, nil
+}
+
+func (builder *BottomUpBuilder) buildNonLeafLevel(childPages []builtPage, level int) ([]builtPage, error) {
+	var pages []builtPage
+	page := &Page{level: level, childPageIds: []int{childPages[0].id}, compressKeyPrefix: builder.pagePool.keyPrefixCompression}
+	firstKey := childPages[0].firstKey
+
+	for _, childPage := range childPages[1:] {
+		page.childPageIds = append(page.childPageIds, childPage.id)
+		page.insertAt(len(page.keyValuePairs), KeyValuePair{key: childPage.firstKey})
+		if page.size() > builder.allowedPageSize && len(page.childPageIds) > 2 {
+			page.deleteChildrenRange(len(page.childPageIds)-1, len(page.childPageIds))
+
+			nonLeafPage, err := builder.write(page, firstKey)
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, nonLeafPage)
+			page = &Page{level: level, childPageIds: []int{childPage.id}, compressKeyPrefix: builder.pagePool.keyPrefixCompression}
+			firstKey = childPage.firstKey
+		}
+	}
+	nonLeafPage, err := builder.write(page, firstKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(pages, nonLeafPage), nil
+}
+
+func (builder *BottomUpBuilder) write(page *Page, firstKey []byte) (builtPage, error) {
+	pageId, err := builder.pagePool.Allocate(1)
+	if err != nil {
+		return builtPage{}, err
+	}
+	page.id = pageId
+	if err := builder.pagePool.Write(page); err != nil {
+		return builtPage{}, err
+	}
+	return builtPage{id: pageId, firstKey: firstKey}, nil
+}
@@ -0,0 +1,52 @@
+package index
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBuildsASingleLeafRootPageGivenKeyValuePairsFitInAPage(t *testing.T) {
+	options := DefaultOptions()
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(metaPageCount)
+
+	builder := NewBottomUpBuilder(pagePool, options.AllowedPageOccupancyPercentage)
+	_ = builder.Add(KeyValuePair{key: []byte("A"), value: []byte("Storage")})
+	_ = builder.Add(KeyValuePair{key: []byte("B"), value: []byte("Database")})
+	rootPageId, _ := builder.Finish()
+
+	rootPage, _ := pagePool.Read(rootPageId)
+	if !rootPage.isLeaf() || len(rootPage.keyValuePairs) != 2 {
+		t.Fatalf("Expected root page to be a leaf with 2 key value pairs, received %v", rootPage.keyValuePairs)
+	}
+}
+
+func TestBuildsATreeWithPagesPackedTillTheAllowedPageOccupancy(t *testing.T) {
+	options := DefaultOptions()
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(metaPageCount)
+
+	builder := NewBottomUpBuilder(pagePool, options.AllowedPageOccupancyPercentage)
+	for index := 1000; index < 2000; index++ {
+		_ = builder.Add(KeyValuePair{key: []byte("Key" + strconv.Itoa(index)), value: []byte("Value" + strconv.Itoa(index))})
+	}
+	rootPageId, _ := builder.Finish()
+
+	pageHierarchy := NewPageHierarchy(pagePool, options.AllowedPageOccupancyPercentage, DefaultFreePageList(0))
+	_ = pageHierarchy.loadRootPage(rootPageId)
+
+	allowedPageSize := options.AllowedPageOccupancyPercentage * options.PageSize / 100
+	for _, childPageId := range pageHierarchy.rootPage.childPageIds[:len(pageHierarchy.rootPage.childPageIds)-1] {
+		childPage, _ := pageHierarchy.fetchOrCachePage(childPageId)
+		if childPage.size() > allowedPageSize || childPage.size() < allowedPageSize-40 {
+			t.Fatalf("Expected leaf page to be packed close to %v bytes, received %v", allowedPageSize, childPage.size())
+		}
+	}
+	for index := 1000; index < 2000; index++ {
+		expected := KeyValuePair{key: []byte("Key" + strconv.Itoa(index)), value: []byte("Value" + strconv.Itoa(index))}
+		getResult := pageHierarchy.Get(expected.key)
+		if !expected.Equals(getResult.KeyValuePair) {
+			t.Fatalf("Expected key value pair to be %v, received %v", expected, getResult.KeyValuePair)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package index
+
+import (
+	"b+tree/index/schema"
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+)
+
+// Compression selects the codec used for leaf pages. The codec of a page is recorded in its header, so pages
+// written with different codecs can be read regardless of the Compression in use.
+type Compression uint8
+
+const (
+	NoCompression Compression = iota
+	FlateCompression
+	LZCompression
+)
+
+type codec interface {
+	compress(source []byte) ([]byte, error)
+	decompress(source []byte, maxLength int) ([]byte, error)
+}
+
+func codecOf(compression Compression) (codec, error) {
+	switch compression {
+	case FlateCompression:
+		return flateCodec{}, nil
+	case LZCompression:
+		return lzCodec{}, nil
+	}
+	return nil, fmt.Errorf("unknown compression %v", compression)
+}
+
+type flateCodec struct{}
+
+func (flateCodec) compress(source []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(source); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (flateCodec) decompress(source []byte, maxLength int) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(source))
+	defer func() { _ = reader.Close() }()
+
+	destination, err := io.ReadAll(io.LimitReader(reader, int64(maxLength)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(destination) > maxLength {
+		return nil, fmt.Errorf("decompressed page exceeds %v bytes", maxLength)
+	}
+	return destination, nil
+}
+
+// compressPage compresses everything after the header of a page laid out by MarshalBinary. It returns nil when
+// the compressed page would not be smaller.
+func compressPage(buffer []byte, compression Compression) ([]byte, error) {
+	codec, err := codecOf(compression)
+	if err != nil {
+		return nil, err
+	}
+	body, err := codec.compress(buffer[pageHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+	if pageHeaderSize+len(body) >= len(buffer) {
+		return nil, nil
+	}
+	header, _ := UnMarshalPageHeader(buffer)
+	header.Codec = uint8(compression)
+	header.CompressedLength = uint32(len(body))
+
+	compressed := make([]byte, pageHeaderSize+len(body))
+	_, _ = header.Marshal(compressed)
+	copy(compressed[pageHeaderSize:], body)
+	return compressed, nil
+}
+
+// decompressPage returns the page laid out as it was before compressPage, the page may not grow beyond the buffer.
+func decompressPage(buffer []byte, header *schema.PersistentPageHeader) ([]byte, error) {
+	codec, err := codecOf(Compression(header.Codec))
+	if err != nil {
+		return nil, err
+	}
+	if int(header.CompressedLength) > len(buffer)-pageHeaderSize {
+		return nil, fmt.Errorf("compressed length %v exceeds the page", header.CompressedLength)
+	}
+	body, err := codec.decompress(buffer[pageHeaderSize:pageHeaderSize+int(header.CompressedLength)], len(buffer)-pageHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, pageHeaderSize+len(body)), buffer[:pageHeaderSize]...), body...), nil
+}
@@ -0,0 +1,67 @@
+package index
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompressesAndDecompressesWithFlateCodec(t *testing.T) {
+	source := []byte(strings.Repeat(`{"tenant":"0001","status":"active"},`, 40))
+
+	compressed, _ := flateCodec{}.compress(source)
+	decompressed, err := flateCodec{}.decompress(compressed, len(source))
+	if err != nil || !bytes.Equal(source, decompressed) {
+		t.Fatalf("Expected the decompressed bytes to match the source, received error %v", err)
+	}
+}
+
+func TestFailsToDecompressBeyondTheMaximumLengthWithFlateCodec(t *testing.T) {
+	compressed, _ := flateCodec{}.compress(bytes.Repeat([]byte("A"), 1000))
+
+	_, err := flateCodec{}.decompress(compressed, 100)
+	if err == nil {
+		t.Fatalf("Expected an error while decompressing beyond the maximum length")
+	}
+}
+
+func TestCompressesAPageAndRecordsTheCodecInTheHeader(t *testing.T) {
+	page := &Page{keyValuePairs: []KeyValuePair{
+		{key: []byte("A"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+		{key: []byte("B"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+	}}
+	compressed, _ := compressPage(page.MarshalBinary(), LZCompression)
+	header, _ := UnMarshalPageHeader(compressed)
+
+	if Compression(header.Codec) != LZCompression || len(compressed) >= page.size() {
+		t.Fatalf("Expected a smaller page with LZCompression in the header, received %v bytes with codec %v", len(compressed), header.Codec)
+	}
+}
+
+func TestUnMarshalsACompressedPage(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+		{key: []byte("B"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+	}
+	compressed, _ := compressPage((&Page{keyValuePairs: keyValuePairs}).MarshalBinary(), FlateCompression)
+	buffer := make([]byte, 4096)
+	copy(buffer, compressed)
+
+	page := &Page{}
+	if err := page.UnMarshalBinary(buffer); err != nil {
+		t.Fatalf("Expected the compressed page to be unmarshalled, received %v", err)
+	}
+	if !reflect.DeepEqual(keyValuePairs, page.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", keyValuePairs, page.AllKeyValuePairs())
+	}
+}
+
+func TestDoesNotCompressAPageThatDoesNotGetSmaller(t *testing.T) {
+	page := &Page{keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}}
+
+	compressed, err := compressPage(page.MarshalBinary(), LZCompression)
+	if compressed != nil || err != nil {
+		t.Fatalf("Expected no compressed page, received %v bytes with error %v", len(compressed), err)
+	}
+}
@@ -0,0 +1,112 @@
+package index
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type DOTOptions struct {
+	// MaxDepth limits the levels rendered below the root page, the root page being at depth 1.
+	// The children of the pages at the last rendered level are collapsed into a single node. 0 renders all the levels
+	MaxDepth int
+
+	// MaxKeyLength truncates the keys longer than this many bytes. 0 renders the keys in full
+	MaxKeyLength int
+}
+
+// WriteDOT renders the tree in the Graphviz DOT language, each page as a record holding its id and its keys,
+// with an edge from every key gap of a non-leaf page to the child page it leads to.
+func (tree BPlusTree) WriteDOT(writer io.Writer, options DOTOptions) error {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	dotWriter := &dotWriter{writer: writer, options: options, pageHierarchy: tree.pageHierarchy, visited: map[int]bool{}}
+	dotWriter.printf("digraph bplustree {\n")
+	dotWriter.printf("  node [shape=record, fontname=\"monospace\"];\n")
+	dotWriter.writePage(tree.pageHierarchy.RootPageId(), 1)
+	dotWriter.printf("}\n")
+	return dotWriter.err
+}
+
+type dotWriter struct {
+	writer        io.Writer
+	options       DOTOptions
+	pageHierarchy *PageHierarchy
+	visited       map[int]bool
+	err           error
+}
+
+// writePage writes a page reachable more than once only the first time, the edges leading to it show the problem.
+func (dotWriter *dotWriter) writePage(pageId int, depth int) {
+	if dotWriter.visited[pageId] {
+		return
+	}
+	dotWriter.visited[pageId] = true
+	page, err := dotWriter.pageHierarchy.cachedOrReadPage(pageId)
+	if err != nil {
+		dotWriter.printf("  page%v [label=\"page %v|%v\", color=red];\n", pageId, pageId, escapeRecordLabel(err.Error()))
+		return
+	}
+	if page.isLeaf() {
+		keys := make([]string, 0, len(page.keyValuePairs))
+		for _, keyValuePair := range page.keyValuePairs {
+			keys = append(keys, dotWriter.label(keyValuePair.key))
+		}
+		dotWriter.printf("  page%v [label=\"{page %v|{%v}}\"];\n", pageId, pageId, strings.Join(keys, "|"))
+		return
+	}
+
+	fields := make([]string, 0, 2*len(page.childPageIds))
+	for index := range page.childPageIds {
+		if index > 0 && index-1 < len(page.keyValuePairs) {
+			fields = append(fields, dotWriter.label(page.keyValuePairs[index-1].key))
+		}
+		fields = append(fields, fmt.Sprintf("<c%v>", index))
+	}
+	dotWriter.printf("  page%v [label=\"{page %v|{%v}}\"];\n", pageId, pageId, strings.Join(fields, "|"))
+
+	if dotWriter.options.MaxDepth > 0 && depth >= dotWriter.options.MaxDepth {
+		dotWriter.printf("  page%vchildren [label=\"%v child pages\", shape=plaintext];\n", pageId, len(page.childPageIds))
+		dotWriter.printf("  page%v -> page%vchildren [style=dashed];\n", pageId, pageId)
+		return
+	}
+	for index, childPageId := range page.childPageIds {
+		dotWriter.printf("  page%v:c%v -> page%v;\n", pageId, index, childPageId)
+	}
+	for _, childPageId := range page.childPageIds {
+		dotWriter.writePage(childPageId, depth+1)
+	}
+}
+
+func (dotWriter *dotWriter) label(key []byte) string {
+	if dotWriter.options.MaxKeyLength > 0 && len(key) > dotWriter.options.MaxKeyLength {
+		return escapeRecordLabel(string(key[:dotWriter.options.MaxKeyLength])) + "..."
+	}
+	return escapeRecordLabel(string(key))
+}
+
+func (dotWriter *dotWriter) printf(format string, args ...interface{}) {
+	if dotWriter.err == nil {
+		_, dotWriter.err = fmt.Fprintf(dotWriter.writer, format, args...)
+	}
+}
+
+// escapeRecordLabel escapes the characters that have a meaning in a record label, and writes the bytes that are
+// not printable as \xNN.
+func escapeRecordLabel(text string) string {
+	var builder strings.Builder
+	for index := 0; index < len(text); index++ {
+		character := text[index]
+		switch {
+		case strings.IndexByte(`\"{}|<> `, character) >= 0:
+			builder.WriteByte('\\')
+			builder.WriteByte(character)
+		case character < 0x20 || character > 0x7E:
+			_, _ = fmt.Fprintf(&builder, "\\\\x%02x", character)
+		default:
+			builder.WriteByte(character)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,86 @@
+package index
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritesALeafRootPageAsDOT(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+	_ = tree.Put([]byte("A"), []byte("Database"))
+	_ = tree.Put([]byte("B"), []byte("Storage"))
+
+	buffer := &bytes.Buffer{}
+	_ = tree.WriteDOT(buffer, DOTOptions{})
+
+	expected := "digraph bplustree {\n" +
+		"  node [shape=record, fontname=\"monospace\"];\n" +
+		"  page1 [label=\"{page 1|{A|B}}\"];\n" +
+		"}\n"
+	if buffer.String() != expected {
+		t.Fatalf("Expected DOT to be %v, received %v", expected, buffer.String())
+	}
+}
+
+func TestWritesANonLeafPageWithEdgesToItsChildPagesAsDOT(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("C")}, {key: []byte("D")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+
+	buffer := &bytes.Buffer{}
+	_ = tree.WriteDOT(buffer, DOTOptions{})
+
+	for _, expected := range []string{
+		"  page1 [label=\"{page 1|{<c0>|C|<c1>}}\"];\n",
+		"  page1:c0 -> page2;\n",
+		"  page1:c1 -> page3;\n",
+		"  page2 [label=\"{page 2|{A}}\"];\n",
+		"  page3 [label=\"{page 3|{C|D}}\"];\n",
+	} {
+		if !strings.Contains(buffer.String(), expected) {
+			t.Fatalf("Expected DOT to contain %v, received %v", expected, buffer.String())
+		}
+	}
+}
+
+func TestCollapsesTheChildPagesBeyondTheMaxDepthInDOT(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+
+	buffer := &bytes.Buffer{}
+	_ = tree.WriteDOT(buffer, DOTOptions{MaxDepth: 1})
+
+	rootPageId := tree.pageHierarchy.RootPageId()
+	if strings.Count(buffer.String(), "[label=") != 2 || !strings.Contains(buffer.String(), "child pages") {
+		t.Fatalf("Expected only the root page %v and its collapsed child pages, received %v", rootPageId, buffer.String())
+	}
+}
+
+func TestTruncatesAndEscapesTheKeysInDOT(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+	_ = tree.Put([]byte("a|b {c}"), []byte("Database"))
+	_ = tree.Put([]byte{0x01, 'z'}, []byte("Storage"))
+
+	buffer := &bytes.Buffer{}
+	_ = tree.WriteDOT(buffer, DOTOptions{MaxKeyLength: 5})
+
+	expected := `  page1 [label="{page 1|{\\x01z|a\|b\ \{...}}"];` + "\n"
+	if !strings.Contains(buffer.String(), expected) {
+		t.Fatalf("Expected DOT to contain %v, received %v", expected, buffer.String())
+	}
+}
+
+func TestWritesAPageReachableMoreThanOnceOnceInDOT(t *testing.T) {
+	rootPage := &Page{id: 1, level: 2, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 2}}
+	childPage := &Page{id: 2, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("B")}}, childPageIds: []int{1, 1}}
+	tree := createInMemoryTreeWithPages(rootPage, childPage)
+
+	buffer := &bytes.Buffer{}
+	if err := tree.WriteDOT(buffer, DOTOptions{}); err != nil {
+		t.Fatalf("Expected no error while writing DOT, received %v", err)
+	}
+	if strings.Count(buffer.String(), "  page2 [label=") != 1 || !strings.Contains(buffer.String(), "  page2:c0 -> page1;\n") {
+		t.Fatalf("Expected page 2 written once with an edge back to page 1, received %v", buffer.String())
+	}
+}
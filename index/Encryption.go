@@ -0,0 +1,115 @@
+package index
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const aesGCMEncryption = uint8(0x01)
+
+var ErrPageEncrypted = errors.New("page is encrypted")
+
+// KeyProvider supplies the AES keys (16, 24 or 32 bytes) used to encrypt the pages. Pages are encrypted with the
+// current key and record its id, older pages are decrypted with the key looked up by the id they record, which lets
+// the keys be rotated without re-encrypting the file at once. Key ids must not be 0.
+type KeyProvider interface {
+	CurrentKey() (uint32, []byte, error)
+	Key(keyId uint32) ([]byte, error)
+}
+
+// encryptPage seals everything after the header of a page laid out by MarshalBinary (compressed or not) with
+// AES-GCM. The nonce and the tag are kept in the header, and the header along with the page id is authenticated so
+// that neither the header nor the location of the page can be altered.
+func (pagePool *PagePool) encryptPage(pageId int, buffer []byte) ([]byte, error) {
+	keyId, key, err := pagePool.keyProvider.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if keyId == 0 {
+		return nil, fmt.Errorf("key id must not be 0")
+	}
+	aead, err := pagePool.aeadOf(keyId, key)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := UnMarshalPageHeader(buffer)
+	header.Encryption = aesGCMEncryption
+	header.KeyId = keyId
+	header.EncryptedLength = uint32(len(buffer) - pageHeaderSize)
+	if _, err := rand.Read(header.Nonce[:]); err != nil {
+		return nil, err
+	}
+	sealed := make([]byte, pageHeaderSize, len(buffer)+aead.Overhead())
+	_, _ = header.Marshal(sealed)
+
+	sealed = aead.Seal(sealed, header.Nonce[:], buffer[pageHeaderSize:], additionalData(sealed[:pageHeaderSize], pageId))
+	copy(header.Tag[:], sealed[len(sealed)-aead.Overhead():])
+	sealed = sealed[:len(sealed)-aead.Overhead()]
+	_, _ = header.Marshal(sealed)
+	return sealed, nil
+}
+
+// decryptPage authenticates and decrypts the page into a buffer of the same length, with the header marked as
+// not encrypted.
+func (pagePool PagePool) decryptPage(pageId int, buffer []byte) ([]byte, error) {
+	header, err := UnMarshalPageHeader(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if header.Encryption != aesGCMEncryption {
+		return nil, fmt.Errorf("page %v has an unknown encryption %v", pageId, header.Encryption)
+	}
+	if pagePool.keyProvider == nil {
+		return nil, fmt.Errorf("page %v: %w, a KeyProvider is required", pageId, ErrPageEncrypted)
+	}
+	if int(header.EncryptedLength) > len(buffer)-pageHeaderSize {
+		return nil, fmt.Errorf("page %v has an encrypted length %v beyond the page", pageId, header.EncryptedLength)
+	}
+	key, err := pagePool.keyProvider.Key(header.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := pagePool.aeadOf(header.KeyId, key)
+	if err != nil {
+		return nil, err
+	}
+	tag := header.Tag
+	header.Tag = [16]byte{}
+	decrypted := make([]byte, len(buffer))
+	_, _ = header.Marshal(decrypted)
+
+	ciphertext := append(append([]byte(nil), buffer[pageHeaderSize:pageHeaderSize+int(header.EncryptedLength)]...), tag[:]...)
+	if _, err := aead.Open(decrypted[pageHeaderSize:pageHeaderSize], header.Nonce[:], ciphertext, additionalData(decrypted[:pageHeaderSize], pageId)); err != nil {
+		return nil, fmt.Errorf("page %v failed the authentication: %w", pageId, err)
+	}
+	header.Encryption = 0
+	_, _ = header.Marshal(decrypted)
+	return decrypted, nil
+}
+
+func (pagePool PagePool) aeadOf(keyId uint32, key []byte) (cipher.AEAD, error) {
+	if aead, ok := pagePool.aeadByKeyId.Load(keyId); ok {
+		return aead.(cipher.AEAD), nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	pagePool.aeadByKeyId.Store(keyId, aead)
+	return aead, nil
+}
+
+func additionalData(header []byte, pageId int) []byte {
+	data := make([]byte, len(header)+8)
+	copy(data, header)
+	binary.LittleEndian.PutUint64(data[len(header):], uint64(pageId))
+	return data
+}
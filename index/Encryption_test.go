@@ -0,0 +1,159 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type keyRing struct {
+	currentKeyId uint32
+	keys         map[uint32][]byte
+}
+
+func newKeyRing() *keyRing {
+	return &keyRing{currentKeyId: 1, keys: map[uint32][]byte{1: bytes.Repeat([]byte{0x01}, 32)}}
+}
+
+func (keyRing *keyRing) CurrentKey() (uint32, []byte, error) {
+	return keyRing.currentKeyId, keyRing.keys[keyRing.currentKeyId], nil
+}
+
+func (keyRing *keyRing) Key(keyId uint32) ([]byte, error) {
+	key, ok := keyRing.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %v", keyId)
+	}
+	return key, nil
+}
+
+func (keyRing *keyRing) rotate(keyId uint32, key []byte) {
+	keyRing.keys[keyId] = key
+	keyRing.currentKeyId = keyId
+}
+
+func newEncryptedPagePool(pageCount int, keyProvider KeyProvider) *PagePool {
+	options := Options{PageSize: os.Getpagesize(), InMemory: true, KeyProvider: keyProvider}
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(pageCount)
+	return pagePool
+}
+
+func TestWritesAndReadsAnEncryptedPage(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	keyValuePairs := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}, {key: []byte("B"), value: []byte("Storage")}}
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: keyValuePairs})
+
+	page, err := pagePool.Read(1)
+	if err != nil {
+		t.Fatalf("Expected the encrypted page to be read, received %v", err)
+	}
+	if !reflect.DeepEqual(keyValuePairs, page.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", keyValuePairs, page.AllKeyValuePairs())
+	}
+}
+
+func TestDoesNotStoreThePlainTextOfAnEncryptedPage(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(1), pagePool.pageSize)
+	if bytes.Contains(buffer, []byte("Database")) {
+		t.Fatalf("Expected the value to be encrypted in the page")
+	}
+}
+
+func TestViewsAnEncryptedPage(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	view, err := pagePool.View(1)
+	if err != nil {
+		t.Fatalf("Expected the encrypted page to be viewed, received %v", err)
+	}
+	keyValuePair, _ := view.keyValuePairAt(0)
+	if !bytes.Equal([]byte("Database"), keyValuePair.value) {
+		t.Fatalf("Expected value to be Database, received %v", keyValuePair.PrettyValue())
+	}
+}
+
+func TestFailsToReadAnEncryptedPageThatWasTamperedWith(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(1), pagePool.pageSize)
+	tampered := append([]byte(nil), buffer...)
+	tampered[pageHeaderSize] = tampered[pageHeaderSize] ^ 0xFF
+	_ = pagePool.storage.WritePage(pagePool.offsetOf(1), tampered)
+
+	if _, err := pagePool.Read(1); err == nil {
+		t.Fatalf("Expected an error while reading a tampered page")
+	}
+}
+
+func TestFailsToReadAnEncryptedPageCopiedToAnotherPage(t *testing.T) {
+	pagePool := newEncryptedPagePool(3, newKeyRing())
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(1), pagePool.pageSize)
+	_ = pagePool.storage.WritePage(pagePool.offsetOf(2), append([]byte(nil), buffer...))
+
+	if _, err := pagePool.Read(2); err == nil {
+		t.Fatalf("Expected an error while reading a page copied from another page")
+	}
+}
+
+func TestReadsAPageEncryptedWithAnOlderKeyAfterRotatingTheKey(t *testing.T) {
+	keyRing := newKeyRing()
+	pagePool := newEncryptedPagePool(3, keyRing)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	keyRing.rotate(2, bytes.Repeat([]byte{0x02}, 16))
+	_ = pagePool.Write(&Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("B"), value: []byte("Storage")}}})
+
+	for pageId, expected := range map[int]string{1: "Database", 2: "Storage"} {
+		page, err := pagePool.Read(pageId)
+		if err != nil {
+			t.Fatalf("Expected page %v to be read, received %v", pageId, err)
+		}
+		if !bytes.Equal([]byte(expected), page.AllKeyValuePairs()[0].value) {
+			t.Fatalf("Expected value to be %v, received %v", expected, page.AllKeyValuePairs()[0].PrettyValue())
+		}
+	}
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(2), pagePool.pageSize)
+	if header, _ := UnMarshalPageHeader(buffer); header.KeyId != 2 {
+		t.Fatalf("Expected key id 2 in the page header, received %v", header.KeyId)
+	}
+}
+
+func TestFailsToReadAnEncryptedPageWithoutAKeyProvider(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+	pagePool.keyProvider = nil
+
+	if _, err := pagePool.Read(1); !errors.Is(err, ErrPageEncrypted) {
+		t.Fatalf("Expected ErrPageEncrypted, received %v", err)
+	}
+}
+
+func TestWritesAndReadsAnEncryptedCompressedPage(t *testing.T) {
+	pagePool := newEncryptedPagePool(2, newKeyRing())
+	pagePool.compression = LZCompression
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: bytes.Repeat([]byte(`{"status":"active"}`), 10)},
+		{key: []byte("B"), value: bytes.Repeat([]byte(`{"status":"active"}`), 10)},
+	}
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: keyValuePairs})
+
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(1), pagePool.pageSize)
+	if header, _ := UnMarshalPageHeader(buffer); Compression(header.Codec) != LZCompression {
+		t.Fatalf("Expected LZCompression in the page header, received %v", header.Codec)
+	}
+	page, err := pagePool.Read(1)
+	if err != nil || !reflect.DeepEqual(keyValuePairs, page.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v with error %v", keyValuePairs, page.AllKeyValuePairs(), err)
+	}
+}
@@ -0,0 +1,16 @@
+package index
+
+import (
+	"os"
+	"syscall"
+)
+
+// allocateFile grows the file to sizeInBytes using fallocate so that the disk space is actually reserved,
+// falling back to truncate on file systems that do not support fallocate.
+func allocateFile(file *os.File, currentSizeInBytes, sizeInBytes int64) error {
+	err := syscall.Fallocate(int(file.Fd()), 0, currentSizeInBytes, sizeInBytes-currentSizeInBytes)
+	if err == syscall.EOPNOTSUPP || err == syscall.ENOSYS {
+		return file.Truncate(sizeInBytes)
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package index
+
+import "os"
+
+func allocateFile(file *os.File, currentSizeInBytes, sizeInBytes int64) error {
+	return file.Truncate(sizeInBytes)
+}
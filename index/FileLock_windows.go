@@ -0,0 +1,42 @@
+package index
+
+import (
+	"math"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const lockRetryInterval = 10 * time.Millisecond
+
+// lockFile locks a single byte at the largest offset rather than the whole file, a byte range lock on Windows blocks
+// the reads and writes of the range by the other handles.
+func lockFile(file *os.File, exclusive bool, timeout time.Duration) error {
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if exclusive {
+		flags = flags | windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, lockOverlapped())
+		if err == nil {
+			return nil
+		}
+		if err != windows.ERROR_LOCK_VIOLATION {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			return ErrLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, lockOverlapped())
+}
+
+func lockOverlapped() *windows.Overlapped {
+	return &windows.Overlapped{Offset: math.MaxUint32, OffsetHigh: math.MaxUint32}
+}
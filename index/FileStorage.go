@@ -0,0 +1,91 @@
+package index
+
+import (
+	"io"
+	"os"
+)
+
+// FileStorage reads and writes pages using pread/pwrite system calls instead of a memory map.
+type FileStorage struct {
+	file     *os.File
+	size     int64
+	readOnly bool
+}
+
+func OpenFileStorage(options Options) (*FileStorage, error) {
+	file, err := openAndLockFile(options)
+	if err != nil {
+		return nil, err
+	}
+	fileStorage := &FileStorage{file: file, readOnly: options.ReadOnly}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = fileStorage.Close()
+		return nil, err
+	}
+	fileStorage.size = stat.Size()
+	return fileStorage, nil
+}
+
+func (fileStorage *FileStorage) ReadPage(offset int64, size int) ([]byte, error) {
+	buf := make([]byte, size)
+	bytesRead, err := fileStorage.file.ReadAt(buf, offset)
+	if bytesRead < size {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (fileStorage *FileStorage) WritePage(offset int64, buffer []byte) error {
+	if fileStorage.readOnly {
+		return ErrReadOnly
+	}
+	_, err := fileStorage.file.WriteAt(buffer, offset)
+	return err
+}
+
+func (fileStorage *FileStorage) Grow(sizeInBytes int64) error {
+	if fileStorage.readOnly {
+		return ErrReadOnly
+	}
+	if sizeInBytes <= fileStorage.size {
+		return fileStorage.Truncate(sizeInBytes)
+	}
+	if err := allocateFile(fileStorage.file, fileStorage.size, sizeInBytes); err != nil {
+		return err
+	}
+	fileStorage.size = sizeInBytes
+	return nil
+}
+
+func (fileStorage *FileStorage) Truncate(sizeInBytes int64) error {
+	if fileStorage.readOnly {
+		return ErrReadOnly
+	}
+	if err := fileStorage.file.Truncate(sizeInBytes); err != nil {
+		return err
+	}
+	fileStorage.size = sizeInBytes
+	return nil
+}
+
+func (fileStorage *FileStorage) Sync() error {
+	if fileStorage.readOnly {
+		return nil
+	}
+	return fileStorage.file.Sync()
+}
+
+func (fileStorage *FileStorage) Size() int64 {
+	return fileStorage.size
+}
+
+func (fileStorage *FileStorage) Close() error {
+	if err := unlockFile(fileStorage.file); err != nil {
+		return err
+	}
+	return fileStorage.file.Close()
+}
@@ -0,0 +1,62 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestCreatesANewFileStorageWithFileSize(t *testing.T) {
+	options := DefaultOptions()
+	fileStorage, _ := OpenFileStorage(options)
+	defer deleteFile(fileStorage)
+
+	expectedFileSize := int64(0)
+	actualFileSize := fileStorage.Size()
+
+	if actualFileSize != expectedFileSize {
+		t.Fatalf("Expected file size to be %v, received %v", expectedFileSize, actualFileSize)
+	}
+}
+
+func TestGrowsAFileStorageToAGivenSize(t *testing.T) {
+	options := DefaultOptions()
+	fileStorage, _ := OpenFileStorage(options)
+	defer deleteFile(fileStorage)
+
+	_ = fileStorage.Grow(int64(options.PageSize))
+
+	stat, _ := os.Stat(options.FileName)
+	expectedFileSize := int64(options.PageSize)
+
+	if fileStorage.Size() != expectedFileSize || stat.Size() != expectedFileSize {
+		t.Fatalf("Expected file size to be %v, received %v and %v on disk", expectedFileSize, fileStorage.Size(), stat.Size())
+	}
+}
+
+func TestWritesAndReadsAPageInFileStorage(t *testing.T) {
+	options := DefaultOptions()
+	fileStorage, _ := OpenFileStorage(options)
+	defer deleteFile(fileStorage)
+
+	_ = fileStorage.Grow(int64(options.PageSize * 2))
+	_ = fileStorage.WritePage(int64(options.PageSize), []byte("Storage"))
+
+	buffer, _ := fileStorage.ReadPage(int64(options.PageSize), options.PageSize)
+	if !bytes.HasPrefix(buffer, []byte("Storage")) {
+		t.Fatalf("Expected page to begin with Storage, received %v", buffer[:7])
+	}
+}
+
+func TestDoesNotReadAPageBeyondTheFileStorageSize(t *testing.T) {
+	options := DefaultOptions()
+	fileStorage, _ := OpenFileStorage(options)
+	defer deleteFile(fileStorage)
+
+	_ = fileStorage.Grow(int64(options.PageSize))
+
+	_, err := fileStorage.ReadPage(int64(options.PageSize), options.PageSize)
+	if err == nil {
+		t.Fatalf("Expected an error while reading a page beyond the file size")
+	}
+}
@@ -1,5 +1,7 @@
 package index
 
+import "sort"
+
 type FreePageList struct {
 	pageIds []int
 }
@@ -15,6 +17,11 @@ func InitializeFreePageList(startingPageId int, pageCount int) *FreePageList {
 	return freePageList
 }
 
+func (freePageList *FreePageList) release(pageIds []int) {
+	freePageList.pageIds = append(freePageList.pageIds, pageIds...)
+	sort.Ints(freePageList.pageIds)
+}
+
 func (freePageList *FreePageList) allocateAndUpdate(pages int) int {
 	firstFreePageId, remainingFreePageIds := freePageList.allocateContiguous(pages)
 	freePageList.pageIds = remainingFreePageIds
@@ -69,3 +69,15 @@ func TestDoesNotAllocate4Pages(t *testing.T) {
 		t.Fatalf("Expected first free page id to be %v, received %v", expected, startingPageId)
 	}
 }
+
+func TestReleasesPagesInSortedOrder(t *testing.T) {
+	freePageList := InitializeFreePageList(5, 3)
+	freePageList.release([]int{9, 2, 8})
+
+	freePageIds := freePageList.pageIds
+	expected := []int{2, 5, 6, 7, 8, 9}
+
+	if !reflect.DeepEqual(expected, freePageIds) {
+		t.Fatalf("Expected freePageIds to be %v, received %v", expected, freePageIds)
+	}
+}
@@ -34,3 +34,7 @@ func NewFailedGetResult(err error) GetResult {
 		Err:   err,
 	}
 }
+
+func (getResult GetResult) Found() bool {
+	return getResult.found
+}
@@ -1,45 +1,120 @@
 package index
 
 import (
+	"errors"
 	"github.com/edsrzf/mmap-go"
 	"io"
 	"os"
 )
 
+var ErrLocked = errors.New("index file is locked by another process")
+
 type IndexFile struct {
-	file      *os.File
-	size      int64
-	memoryMap mmap.MMap
+	file          *os.File
+	size          int64
+	memoryMap     mmap.MMap
+	memoryMapSize int64
+	readOnly      bool
 }
 
 func OpenIndexFile(options Options) (*IndexFile, error) {
-	fileMode := os.O_CREATE | os.O_RDWR
-	file, err := os.OpenFile(options.FileName, fileMode, 0644)
-
+	file, err := openAndLockFile(options)
 	if err != nil {
 		return nil, err
 	}
-	indexFile := &IndexFile{file: file}
+	indexFile := &IndexFile{file: file, readOnly: options.ReadOnly, memoryMapSize: options.MemoryMapSize}
 	indexFile.size, _ = indexFile.fileSize()
 
 	if indexFile.size > 0 {
 		if err := indexFile.mMap(); err != nil {
+			_ = indexFile.Close()
 			return nil, err
 		}
 	}
 	return indexFile, nil
 }
 
-func (indexFile *IndexFile) ResizeTo(sizeInBytes int64) error {
-	err := indexFile.unMap()
+func openAndLockFile(options Options) (*os.File, error) {
+	fileMode := os.O_CREATE | os.O_RDWR
+	if options.ReadOnly {
+		fileMode = os.O_RDONLY
+	}
+	file, err := os.OpenFile(options.FileName, fileMode, 0644)
+
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := indexFile.file.Truncate(sizeInBytes); err != nil {
+	if err := lockFile(file, !options.ReadOnly, options.LockTimeout); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (indexFile *IndexFile) ReadPage(offset int64, size int) ([]byte, error) {
+	if offset+int64(size) > indexFile.size {
+		return nil, io.EOF
+	}
+	buf := make([]byte, size)
+	copy(buf, indexFile.memoryMap[offset:])
+	return buf, nil
+}
+
+func (indexFile *IndexFile) ViewPage(offset int64, size int) ([]byte, error) {
+	if offset+int64(size) > indexFile.size {
+		return nil, io.EOF
+	}
+	return indexFile.memoryMap[offset : offset+int64(size) : offset+int64(size)], nil
+}
+
+func (indexFile *IndexFile) WritePage(offset int64, buffer []byte) error {
+	if indexFile.readOnly {
+		return ErrReadOnly
+	}
+	if offset+int64(len(buffer)) > indexFile.size {
+		return io.ErrShortWrite
+	}
+	copy(indexFile.memoryMap[offset:], buffer)
+	return nil
+}
+
+func (indexFile *IndexFile) Grow(sizeInBytes int64) error {
+	return indexFile.ResizeTo(sizeInBytes)
+}
+
+func (indexFile *IndexFile) Truncate(sizeInBytes int64) error {
+	return indexFile.ResizeTo(sizeInBytes)
+}
+
+func (indexFile *IndexFile) Sync() error {
+	if indexFile.readOnly || indexFile.memoryMap == nil {
+		return nil
+	}
+	return indexFile.memoryMap.Flush()
+}
+
+func (indexFile *IndexFile) Size() int64 {
+	return indexFile.size
+}
+
+// ResizeTo grows or shrinks the index file. The file is remapped only if the new size does not fit in the
+// current mapping, which is reserved with memoryMapSize.
+func (indexFile *IndexFile) ResizeTo(sizeInBytes int64) error {
+	if indexFile.readOnly {
+		return ErrReadOnly
+	}
+	if sizeInBytes > indexFile.size {
+		if err := allocateFile(indexFile.file, indexFile.size, sizeInBytes); err != nil {
+			return err
+		}
+	} else if err := indexFile.file.Truncate(sizeInBytes); err != nil {
 		return err
 	}
 
 	indexFile.size = sizeInBytes
+	if sizeInBytes == 0 || (indexFile.memoryMap != nil && sizeInBytes <= int64(len(indexFile.memoryMap))) {
+		return nil
+	}
 	return indexFile.mMap()
 }
 
@@ -48,6 +123,10 @@ func (indexFile *IndexFile) Close() error {
 	if err != nil {
 		return err
 	}
+	err = unlockFile(indexFile.file)
+	if err != nil {
+		return err
+	}
 	err = indexFile.file.Close()
 	if err != nil {
 		return err
@@ -55,19 +134,6 @@ func (indexFile *IndexFile) Close() error {
 	return nil
 }
 
-func (indexFile *IndexFile) readFrom(offset int64, size int) ([]byte, error) {
-	buf := make([]byte, size)
-	elementsCopied := copy(buf, indexFile.memoryMap[offset:])
-	if elementsCopied < size {
-		return nil, io.EOF
-	}
-	return buf, nil
-}
-
-func (indexFile *IndexFile) writeAt(offset int64, buffer []byte) {
-	copy(indexFile.memoryMap[offset:], buffer)
-}
-
 func (indexFile *IndexFile) fileSize() (int64, error) {
 	stat, err := indexFile.file.Stat()
 	if err != nil {
@@ -87,7 +153,14 @@ func (indexFile *IndexFile) mMap() error {
 	if err := indexFile.unMap(); err != nil {
 		return err
 	}
-	memoryMapped, err := mmap.Map(indexFile.file, mmap.RDWR, 0)
+	protection := mmap.RDWR
+	if indexFile.readOnly {
+		protection = mmap.RDONLY
+	}
+	for indexFile.memoryMapSize > 0 && indexFile.memoryMapSize < indexFile.size {
+		indexFile.memoryMapSize = indexFile.memoryMapSize * 2
+	}
+	memoryMapped, err := mmap.MapRegion(indexFile.file, int(mappingSize(indexFile.size, indexFile.memoryMapSize)), protection, 0, 0)
 	if err != nil {
 		return err
 	}
@@ -3,10 +3,16 @@ package index
 import (
 	"os"
 	"testing"
+	"time"
 )
 
-func deleteFile(indexFile *IndexFile) {
-	_ = os.Remove(indexFile.file.Name())
+func deleteFile(storage Storage) {
+	switch storage := storage.(type) {
+	case *IndexFile:
+		_ = os.Remove(storage.file.Name())
+	case *FileStorage:
+		_ = os.Remove(storage.file.Name())
+	}
 }
 
 func TestCreatesANewIndexFileWithFileSize(t *testing.T) {
@@ -68,8 +74,82 @@ func TestClosesTheIndexFile(t *testing.T) {
 	}
 }
 
+func TestDoesNotOpenAnIndexFileLockedByAWriter(t *testing.T) {
+	options := DefaultOptions()
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+	defer func() { _ = indexFile.Close() }()
+
+	options.LockTimeout = 50 * time.Millisecond
+	_, err := OpenIndexFile(options)
+	if err != ErrLocked {
+		t.Fatalf("Expected ErrLocked while opening a locked index file, received %v", err)
+	}
+}
+
+func TestOpensAnIndexFileInReadOnlyModeMoreThanOnce(t *testing.T) {
+	options := Options{
+		PageSize: os.Getpagesize(),
+		FileName: "./test",
+		ReadOnly: true,
+	}
+	createATestFileWithSize(options.FileName, options.PageSize)
+
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+	defer func() { _ = indexFile.Close() }()
+
+	anotherIndexFile, err := OpenIndexFile(options)
+	if err != nil {
+		t.Fatalf("Expected no error while opening an index file in read-only mode twice, received %v", err)
+	}
+	_ = anotherIndexFile.Close()
+}
+
+func TestOpensAnIndexFileAfterTheLockIsReleased(t *testing.T) {
+	options := DefaultOptions()
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+	_ = indexFile.Close()
+
+	reopenedIndexFile, err := OpenIndexFile(options)
+	if err != nil {
+		t.Fatalf("Expected no error while opening an index file after close, received %v", err)
+	}
+	_ = reopenedIndexFile.Close()
+}
+
 func createATestFileWithSize(fileName string, sizeBytes int) {
 	file, _ := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR, 0644)
 	_, _ = file.Write(make([]byte, sizeBytes))
 	_ = file.Close()
 }
+
+func TestDoesNotResizeAReadOnlyIndexFile(t *testing.T) {
+	options := Options{
+		PageSize: os.Getpagesize(),
+		FileName: "./test",
+		ReadOnly: true,
+	}
+	createATestFileWithSize(options.FileName, options.PageSize)
+
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+
+	err := indexFile.ResizeTo(int64(options.PageSize * 2))
+	if err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly while resizing a read-only index file, received %v", err)
+	}
+}
+
+func TestDoesNotOpenAMissingIndexFileInReadOnlyMode(t *testing.T) {
+	options := Options{
+		PageSize: os.Getpagesize(),
+		FileName: "./missing",
+		ReadOnly: true,
+	}
+	_, err := OpenIndexFile(options)
+	if err == nil {
+		t.Fatalf("Expected an error while opening a missing index file in read-only mode")
+	}
+}
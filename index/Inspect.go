@@ -0,0 +1,87 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TreeInfo describes the layout of the index file behind a tree.
+type TreeInfo struct {
+	PageSize   int
+	PageCount  int
+	Height     int
+	RootPageId int
+}
+
+// PageDump is a page as decoded from the index file, ChildPageIds is empty for a leaf page.
+type PageDump struct {
+	PageId        int
+	Leaf          bool
+	Level         int
+	Lsn           uint64
+	KeyValuePairs []KeyValuePair
+	ChildPageIds  []int
+}
+
+func (tree BPlusTree) Info() (TreeInfo, error) {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	height, err := tree.pageHierarchy.height()
+	if err != nil {
+		return TreeInfo{}, err
+	}
+	return TreeInfo{
+		PageSize:   tree.pagePool.pageSize,
+		PageCount:  tree.pagePool.pageCount,
+		Height:     height,
+		RootPageId: tree.pageHierarchy.RootPageId(),
+	}, nil
+}
+
+// DumpPage reads the page from the index file and decodes it, regardless of whether it is reachable from the root.
+func (tree BPlusTree) DumpPage(pageId int) (PageDump, error) {
+	if pageId == metaPageId {
+		return PageDump{}, fmt.Errorf("page %v is the meta page", pageId)
+	}
+	if pageId < 0 || pageId >= tree.pagePool.pageCount {
+		return PageDump{}, fmt.Errorf("page %v is outside the index file of %v pages", pageId, tree.pagePool.pageCount)
+	}
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	page, err := tree.pagePool.Read(pageId)
+	if err != nil {
+		return PageDump{}, err
+	}
+	return PageDump{
+		PageId:        page.id,
+		Leaf:          page.isLeaf(),
+		Level:         page.level,
+		Lsn:           page.lsn,
+		KeyValuePairs: page.keyValuePairs,
+		ChildPageIds:  page.childPageIds,
+	}, nil
+}
+
+func (dump PageDump) String() string {
+	var builder strings.Builder
+	pageType := "non-leaf"
+	if dump.Leaf {
+		pageType = "leaf"
+	}
+	_, _ = fmt.Fprintf(&builder, "page %v: %v, level %v, lsn %v, %v keys\n", dump.PageId, pageType, dump.Level, dump.Lsn, len(dump.KeyValuePairs))
+	if dump.Leaf {
+		for _, keyValuePair := range dump.KeyValuePairs {
+			_, _ = fmt.Fprintf(&builder, "  %v = %v\n", keyValuePair.PrettyKey(), keyValuePair.PrettyValue())
+		}
+		return builder.String()
+	}
+	for index, childPageId := range dump.ChildPageIds {
+		if index > 0 && index-1 < len(dump.KeyValuePairs) {
+			_, _ = fmt.Fprintf(&builder, "  key %v\n", dump.KeyValuePairs[index-1].PrettyKey())
+		}
+		_, _ = fmt.Fprintf(&builder, "  child %v\n", childPageId)
+	}
+	return builder.String()
+}
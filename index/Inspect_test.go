@@ -0,0 +1,55 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReturnsTheInfoOfATree(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+
+	info, err := tree.Info()
+	if err != nil {
+		t.Fatalf("Expected no error while returning the info, received %v", err)
+	}
+	if info.PageSize != tree.pagePool.pageSize || info.PageCount != tree.pagePool.pageCount || info.RootPageId != tree.pageHierarchy.RootPageId() {
+		t.Fatalf("Expected the page size, page count and root page id of the tree, received %v", info)
+	}
+	if info.Height < 2 {
+		t.Fatalf("Expected height to be at least 2, received %v", info.Height)
+	}
+}
+
+func TestDumpsALeafPage(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+	_ = tree.Put([]byte("A"), []byte("Database"))
+
+	dump, err := tree.DumpPage(1)
+	if err != nil {
+		t.Fatalf("Expected no error while dumping a page, received %v", err)
+	}
+	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}
+	if !dump.Leaf || !reflect.DeepEqual(expected, dump.KeyValuePairs) {
+		t.Fatalf("Expected a leaf page with %v, received %v", expected, dump)
+	}
+}
+
+func TestDumpsANonLeafPage(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+
+	dump, err := tree.DumpPage(tree.pageHierarchy.RootPageId())
+	if err != nil {
+		t.Fatalf("Expected no error while dumping a page, received %v", err)
+	}
+	if dump.Leaf || len(dump.ChildPageIds) != len(dump.KeyValuePairs)+1 {
+		t.Fatalf("Expected a non-leaf page with one more child page than keys, received %v", dump)
+	}
+}
+
+func TestFailsToDumpAPageOutsideTheIndexFile(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+
+	if _, err := tree.DumpPage(tree.pagePool.pageCount); err == nil {
+		t.Fatalf("Expected an error while dumping a page outside the index file")
+	}
+}
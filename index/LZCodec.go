@@ -0,0 +1,141 @@
+package index
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// lzCodec is a byte oriented LZ77 codec in the spirit of LZ4. The compressed stream is a series of sequences,
+// each made of a token, literals and a match:
+//
+//	token: literal length (high 4 bits) and match length - 4 (low 4 bits), 15 in either is extended by
+//	       the following bytes, each adding up to 255, till a byte less than 255
+//	literals: the bytes copied as is
+//	match: a 2 byte little endian offset back into the output followed by the extended match length, if any
+//
+// The last sequence carries only literals and ends the stream.
+type lzCodec struct{}
+
+const (
+	lzMinimumMatchLength = 4
+	lzMaximumOffset      = 65535
+	lzHashBits           = 14
+)
+
+var errCorruptLZStream = errors.New("corrupt lz stream")
+
+func (lzCodec) compress(source []byte) ([]byte, error) {
+	var positions [1 << lzHashBits]int32
+	destination := make([]byte, 0, len(source)/2)
+
+	anchor := 0
+	for index := 0; index+lzMinimumMatchLength <= len(source); {
+		sequence := binary.LittleEndian.Uint32(source[index:])
+		hash := (sequence * 2654435761) >> (32 - lzHashBits)
+		candidate := int(positions[hash]) - 1
+		positions[hash] = int32(index + 1)
+
+		if candidate < 0 || index-candidate > lzMaximumOffset || binary.LittleEndian.Uint32(source[candidate:]) != sequence {
+			index++
+			continue
+		}
+		matchLength := lzMinimumMatchLength
+		for index+matchLength < len(source) && source[candidate+matchLength] == source[index+matchLength] {
+			matchLength++
+		}
+		destination = appendLZSequence(destination, source[anchor:index], index-candidate, matchLength)
+		index = index + matchLength
+		anchor = index
+	}
+	return appendLZSequence(destination, source[anchor:], 0, 0), nil
+}
+
+func (lzCodec) decompress(source []byte, maxLength int) ([]byte, error) {
+	destination := make([]byte, 0, maxLength)
+	for index := 0; index < len(source); {
+		token := source[index]
+		index++
+
+		literalLength, next, err := readLZLength(source, index, int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		index = next
+		if literalLength > len(source)-index || len(destination)+literalLength > maxLength {
+			return nil, errCorruptLZStream
+		}
+		destination = append(destination, source[index:index+literalLength]...)
+		index = index + literalLength
+		if index == len(source) {
+			break
+		}
+
+		if index+2 > len(source) {
+			return nil, errCorruptLZStream
+		}
+		offset := int(binary.LittleEndian.Uint16(source[index:]))
+		index = index + 2
+		matchLength, next, err := readLZLength(source, index, int(token&0x0F))
+		if err != nil {
+			return nil, err
+		}
+		index = next
+		matchLength = matchLength + lzMinimumMatchLength
+		if offset == 0 || offset > len(destination) || len(destination)+matchLength > maxLength {
+			return nil, errCorruptLZStream
+		}
+		from := len(destination) - offset
+		for count := 0; count < matchLength; count++ {
+			destination = append(destination, destination[from+count])
+		}
+	}
+	return destination, nil
+}
+
+func appendLZSequence(destination []byte, literals []byte, offset int, matchLength int) []byte {
+	literalNibble, matchNibble := len(literals), 0
+	if literalNibble > 15 {
+		literalNibble = 15
+	}
+	if matchLength > 0 {
+		matchNibble = matchLength - lzMinimumMatchLength
+		if matchNibble > 15 {
+			matchNibble = 15
+		}
+	}
+	destination = append(destination, byte(literalNibble<<4|matchNibble))
+	destination = appendLZLength(destination, len(literals))
+	destination = append(destination, literals...)
+	if matchLength > 0 {
+		destination = append(destination, byte(offset), byte(offset>>8))
+		destination = appendLZLength(destination, matchLength-lzMinimumMatchLength)
+	}
+	return destination
+}
+
+func appendLZLength(destination []byte, length int) []byte {
+	if length < 15 {
+		return destination
+	}
+	for length = length - 15; length >= 255; length = length - 255 {
+		destination = append(destination, 255)
+	}
+	return append(destination, byte(length))
+}
+
+func readLZLength(source []byte, index int, length int) (int, int, error) {
+	if length < 15 {
+		return length, index, nil
+	}
+	for {
+		if index >= len(source) {
+			return 0, 0, errCorruptLZStream
+		}
+		next := source[index]
+		index++
+		length = length + int(next)
+		if next < 255 {
+			return length, index, nil
+		}
+	}
+}
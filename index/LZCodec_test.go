@@ -0,0 +1,71 @@
+package index
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCompressesAndDecompressesRepetitiveJSONWithLZCodec(t *testing.T) {
+	source := []byte(strings.Repeat(`{"tenant":"0001","status":"active","tags":["a","b"]},`, 40))
+
+	compressed, _ := lzCodec{}.compress(source)
+	if len(compressed) >= len(source)/4 {
+		t.Fatalf("Expected repetitive JSON of %v bytes to compress 4x, received %v bytes", len(source), len(compressed))
+	}
+	decompressed, err := lzCodec{}.decompress(compressed, len(source))
+	if err != nil || !bytes.Equal(source, decompressed) {
+		t.Fatalf("Expected the decompressed bytes to match the source, received error %v", err)
+	}
+}
+
+func TestCompressesAndDecompressesRandomBytesWithLZCodec(t *testing.T) {
+	source := make([]byte, 4096)
+	rand.New(rand.NewSource(7)).Read(source)
+
+	compressed, _ := lzCodec{}.compress(source)
+	decompressed, err := lzCodec{}.decompress(compressed, len(source))
+	if err != nil || !bytes.Equal(source, decompressed) {
+		t.Fatalf("Expected the decompressed bytes to match the source, received error %v", err)
+	}
+}
+
+func TestCompressesAndDecompressesLongRunsWithLZCodec(t *testing.T) {
+	source := append(bytes.Repeat([]byte("A"), 1000), append([]byte("0123456789abcdefghijklmnop"), bytes.Repeat([]byte("B"), 300)...)...)
+
+	compressed, _ := lzCodec{}.compress(source)
+	decompressed, err := lzCodec{}.decompress(compressed, len(source))
+	if err != nil || !bytes.Equal(source, decompressed) {
+		t.Fatalf("Expected the decompressed bytes to match the source, received error %v", err)
+	}
+}
+
+func TestCompressesAndDecompressesEmptyBytesWithLZCodec(t *testing.T) {
+	compressed, _ := lzCodec{}.compress(nil)
+	decompressed, err := lzCodec{}.decompress(compressed, 0)
+	if err != nil || len(decompressed) != 0 {
+		t.Fatalf("Expected no decompressed bytes, received %v with error %v", decompressed, err)
+	}
+}
+
+func TestFailsToDecompressACorruptStreamWithLZCodec(t *testing.T) {
+	source := []byte(strings.Repeat("Database Systems ", 20))
+	compressed, _ := lzCodec{}.compress(source)
+	compressed = compressed[:len(compressed)/2]
+
+	_, err := lzCodec{}.decompress(compressed, len(source))
+	if err == nil {
+		t.Fatalf("Expected an error while decompressing a corrupt stream")
+	}
+}
+
+func TestFailsToDecompressBeyondTheMaximumLengthWithLZCodec(t *testing.T) {
+	source := bytes.Repeat([]byte("A"), 1000)
+	compressed, _ := lzCodec{}.compress(source)
+
+	_, err := lzCodec{}.decompress(compressed, 100)
+	if err == nil {
+		t.Fatalf("Expected an error while decompressing beyond the maximum length")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package index
+
+// mappingSize reserves memoryMapSize bytes of the address space past the end of the file, so that growing the file
+// within the reservation does not remap it.
+func mappingSize(fileSize, memoryMapSize int64) int64 {
+	if memoryMapSize > fileSize {
+		return memoryMapSize
+	}
+	return fileSize
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+// +build !windows
+
+package index
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDoesNotRemapTheIndexFileGivenTheNewSizeFitsTheReservedMapping(t *testing.T) {
+	options := DefaultOptions()
+	options.MemoryMapSize = int64(options.PageSize * 4)
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+
+	_ = indexFile.ResizeTo(int64(options.PageSize))
+	mappingBeforeResize := &indexFile.memoryMap[0]
+	_ = indexFile.ResizeTo(int64(options.PageSize * 3))
+
+	if &indexFile.memoryMap[0] != mappingBeforeResize {
+		t.Fatalf("Expected the index file not to be remapped within the reserved mapping")
+	}
+}
+
+func TestDoublesTheReservedMappingGivenTheIndexFileOutgrowsIt(t *testing.T) {
+	options := DefaultOptions()
+	options.MemoryMapSize = int64(options.PageSize * 2)
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+
+	_ = indexFile.ResizeTo(int64(options.PageSize * 3))
+
+	expectedMappingSize := options.PageSize * 4
+	if len(indexFile.memoryMap) != expectedMappingSize {
+		t.Fatalf("Expected mapping size to be %v, received %v", expectedMappingSize, len(indexFile.memoryMap))
+	}
+}
+
+func TestReleasesTheLockGivenTheIndexFileCouldNotBeMapped(t *testing.T) {
+	options := DefaultOptions()
+	createATestFileWithSize(options.FileName, options.PageSize)
+	defer func() { _ = os.Remove(options.FileName) }()
+
+	options.MemoryMapSize = 1 << 62
+	if _, err := OpenIndexFile(options); err == nil {
+		t.Fatalf("Expected an error while mapping more than the address space")
+	}
+	options.MemoryMapSize = DefaultOptions().MemoryMapSize
+	options.LockTimeout = 50 * time.Millisecond
+	indexFile, err := OpenIndexFile(options)
+	if err != nil {
+		t.Fatalf("Expected no error while opening the index file after the failed mapping, received %v", err)
+	}
+	_ = indexFile.Close()
+}
@@ -0,0 +1,7 @@
+package index
+
+// mappingSize maps only the file. CreateFileMapping grows a read-write file to the size of the mapping and refuses
+// a read-only mapping larger than the file, so the file is remapped every time it grows.
+func mappingSize(fileSize, memoryMapSize int64) int64 {
+	return fileSize
+}
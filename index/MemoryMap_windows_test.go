@@ -0,0 +1,35 @@
+package index
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMapsOnlyTheIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	indexFile, _ := OpenIndexFile(options)
+	defer deleteFile(indexFile)
+	defer func() { _ = indexFile.Close() }()
+
+	_ = indexFile.ResizeTo(int64(options.PageSize * 3))
+
+	if len(indexFile.memoryMap) != options.PageSize*3 {
+		t.Fatalf("Expected mapping size to be %v, received %v", options.PageSize*3, len(indexFile.memoryMap))
+	}
+}
+
+func TestOpensAnIndexFileInReadOnlyModeWithoutGrowingIt(t *testing.T) {
+	options := DefaultOptions()
+	options.ReadOnly = true
+	createATestFileWithSize(options.FileName, options.PageSize)
+	defer func() { _ = os.Remove(options.FileName) }()
+
+	indexFile, err := OpenIndexFile(options)
+	if err != nil {
+		t.Fatalf("Expected no error while opening an index file in read-only mode, received %v", err)
+	}
+	_ = indexFile.Close()
+	if stat, _ := os.Stat(options.FileName); stat.Size() != int64(options.PageSize) {
+		t.Fatalf("Expected file size to stay %v, received %v", options.PageSize, stat.Size())
+	}
+}
@@ -0,0 +1,65 @@
+package index
+
+import "io"
+
+// MemoryStorage keeps all the pages in a byte slice. It is meant for tests and short-lived caches,
+// nothing is persisted after Close.
+type MemoryStorage struct {
+	buffer []byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+func (memoryStorage *MemoryStorage) ReadPage(offset int64, size int) ([]byte, error) {
+	if offset+int64(size) > int64(len(memoryStorage.buffer)) {
+		return nil, io.EOF
+	}
+	buf := make([]byte, size)
+	copy(buf, memoryStorage.buffer[offset:])
+	return buf, nil
+}
+
+func (memoryStorage *MemoryStorage) ViewPage(offset int64, size int) ([]byte, error) {
+	if offset+int64(size) > int64(len(memoryStorage.buffer)) {
+		return nil, io.EOF
+	}
+	return memoryStorage.buffer[offset : offset+int64(size) : offset+int64(size)], nil
+}
+
+func (memoryStorage *MemoryStorage) WritePage(offset int64, buffer []byte) error {
+	if offset+int64(len(buffer)) > int64(len(memoryStorage.buffer)) {
+		return io.ErrShortWrite
+	}
+	copy(memoryStorage.buffer[offset:], buffer)
+	return nil
+}
+
+func (memoryStorage *MemoryStorage) Grow(sizeInBytes int64) error {
+	buffer := make([]byte, sizeInBytes)
+	copy(buffer, memoryStorage.buffer)
+	memoryStorage.buffer = buffer
+	return nil
+}
+
+func (memoryStorage *MemoryStorage) Truncate(sizeInBytes int64) error {
+	if sizeInBytes > int64(len(memoryStorage.buffer)) {
+		return memoryStorage.Grow(sizeInBytes)
+	}
+	memoryStorage.buffer = memoryStorage.buffer[:sizeInBytes]
+	return nil
+}
+
+func (memoryStorage *MemoryStorage) Sync() error {
+	return nil
+}
+
+func (memoryStorage *MemoryStorage) Size() int64 {
+	return int64(len(memoryStorage.buffer))
+}
+
+func (memoryStorage *MemoryStorage) Close() error {
+	memoryStorage.buffer = nil
+	return nil
+}
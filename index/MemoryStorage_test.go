@@ -0,0 +1,57 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGrowsAMemoryStorageToAGivenSize(t *testing.T) {
+	memoryStorage := NewMemoryStorage()
+	_ = memoryStorage.Grow(int64(os.Getpagesize()))
+
+	expectedSize := int64(os.Getpagesize())
+	if memoryStorage.Size() != expectedSize {
+		t.Fatalf("Expected size to be %v, received %v", expectedSize, memoryStorage.Size())
+	}
+}
+
+func TestRetainsTheContentOfAMemoryStorageAfterGrowing(t *testing.T) {
+	memoryStorage := NewMemoryStorage()
+	_ = memoryStorage.Grow(int64(os.Getpagesize()))
+	_ = memoryStorage.WritePage(0, []byte("Storage"))
+	_ = memoryStorage.Grow(int64(os.Getpagesize() * 2))
+
+	buffer, _ := memoryStorage.ReadPage(0, os.Getpagesize())
+	if !bytes.HasPrefix(buffer, []byte("Storage")) {
+		t.Fatalf("Expected page to begin with Storage, received %v", buffer[:7])
+	}
+}
+
+func TestDoesNotWriteAPageBeyondTheMemoryStorageSize(t *testing.T) {
+	memoryStorage := NewMemoryStorage()
+
+	err := memoryStorage.WritePage(0, []byte("Storage"))
+	if err == nil {
+		t.Fatalf("Expected an error while writing a page beyond the size")
+	}
+}
+
+func TestWritesAndReadsAPageUsingPagePoolOverMemoryStorage(t *testing.T) {
+	options := DefaultOptions()
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(2)
+
+	page := &Page{
+		id:            1,
+		keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Storage")}},
+	}
+	_ = pagePool.Write(page)
+
+	readPage, _ := pagePool.Read(page.id)
+	expectedKeyValuePair := page.keyValuePairs[0]
+
+	if !expectedKeyValuePair.Equals(readPage.keyValuePairs[0]) {
+		t.Fatalf("Expected key value pair to be %v, received %v", expectedKeyValuePair, readPage.keyValuePairs[0])
+	}
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"b+tree/index/schema"
+	"fmt"
+	"io"
+	"os"
+)
+
+const metaPageId = 0
+
+type MetaPage struct {
+	pageSize                       int
+	allowedPageOccupancyPercentage int
+	rootPageId                     int
+	lsn                            uint64
+	keyId                          uint32
+	pageCount                      int
+}
+
+func NewMetaPage(options Options) *MetaPage {
+	return &MetaPage{
+		pageSize:                       options.PageSize,
+		allowedPageOccupancyPercentage: options.AllowedPageOccupancyPercentage,
+		rootPageId:                     metaPageCount,
+	}
+}
+
+func (metaPage MetaPage) MarshalBinary() []byte {
+	buffer, _ := metaPage.toPersistentMetaPage().Marshal(nil)
+	return buffer
+}
+
+func (metaPage *MetaPage) UnMarshalBinary(buffer []byte) error {
+	persistentMetaPage := schema.PersistentMetaPage{}
+	if len(buffer) < int(persistentMetaPage.Size()) || buffer[0] != MetaDataPage {
+		return fmt.Errorf("index file does not contain a valid meta page")
+	}
+	_, _ = persistentMetaPage.Unmarshal(buffer)
+
+	metaPage.pageSize = int(persistentMetaPage.PageSize)
+	metaPage.allowedPageOccupancyPercentage = int(persistentMetaPage.AllowedPageOccupancyPercentage)
+	rootPageId, err := pageIdOf(persistentMetaPage.RootPageId)
+	if err != nil {
+		return err
+	}
+	metaPage.rootPageId = rootPageId
+	metaPage.lsn = persistentMetaPage.Lsn
+	metaPage.keyId = persistentMetaPage.KeyId
+	pageCount, err := pageIdOf(persistentMetaPage.PageCount)
+	if err != nil {
+		return err
+	}
+	metaPage.pageCount = pageCount
+	return nil
+}
+
+// applyTo returns the options to be used for an existing index file. The page size decides the layout of the file
+// so it must match the one stored in the meta page, while the stored occupancy percentage wins over the passed one.
+// An index file whose pages were encrypted can not be opened without a KeyProvider.
+func (metaPage MetaPage) applyTo(options Options) (Options, error) {
+	if metaPage.pageSize != options.PageSize {
+		return options, fmt.Errorf("PageSize %v does not match the PageSize %v stored in %v", options.PageSize, metaPage.pageSize, options.FileName)
+	}
+	if metaPage.keyId != 0 && options.KeyProvider == nil {
+		return options, fmt.Errorf("%v is encrypted with the key %v, a KeyProvider is required", options.FileName, metaPage.keyId)
+	}
+	options.AllowedPageOccupancyPercentage = metaPage.allowedPageOccupancyPercentage
+	return options, nil
+}
+
+// StoredOptions returns the default options for an existing index file, with the page size and the occupancy
+// percentage stored in its meta page, so that the file can be opened without knowing the page size it was created
+// with.
+func StoredOptions(fileName string) (Options, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return Options{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	buffer := make([]byte, (&schema.PersistentMetaPage{}).Size())
+	if _, err := io.ReadFull(file, buffer); err != nil {
+		return Options{}, fmt.Errorf("%v does not contain a valid meta page: %w", fileName, err)
+	}
+	metaPage := &MetaPage{}
+	if err := metaPage.UnMarshalBinary(buffer); err != nil {
+		return Options{}, err
+	}
+	options := DefaultOptions()
+	options.FileName = fileName
+	options.PageSize = metaPage.pageSize
+	options.AllowedPageOccupancyPercentage = metaPage.allowedPageOccupancyPercentage
+	return options, nil
+}
+
+func (metaPage MetaPage) toPersistentMetaPage() *schema.PersistentMetaPage {
+	return &schema.PersistentMetaPage{
+		PageType:                       MetaDataPage,
+		PageSize:                       uint32(metaPage.pageSize),
+		AllowedPageOccupancyPercentage: uint8(metaPage.allowedPageOccupancyPercentage),
+		RootPageId:                     uint64(metaPage.rootPageId),
+		Lsn:                            metaPage.lsn,
+		KeyId:                          metaPage.keyId,
+		PageCount:                      uint64(metaPage.pageCount),
+	}
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"os"
+	"testing"
+)
+
+func TestUnMarshalsAMetaPage(t *testing.T) {
+	metaPage := NewMetaPage(DefaultOptions())
+
+	readMetaPage := &MetaPage{}
+	err := readMetaPage.UnMarshalBinary(metaPage.MarshalBinary())
+
+	if err != nil {
+		t.Fatalf("Expected no error while unmarshalling meta page, received %v", err)
+	}
+	if *readMetaPage != *metaPage {
+		t.Fatalf("Expected meta page to be %v, received %v", *metaPage, *readMetaPage)
+	}
+}
+
+func TestUnMarshalsAMetaPageWithARootPageIdBeyondTheUint32Range(t *testing.T) {
+	metaPage := NewMetaPage(DefaultOptions())
+	metaPage.rootPageId = 1 << 35
+
+	readMetaPage := &MetaPage{}
+	_ = readMetaPage.UnMarshalBinary(metaPage.MarshalBinary())
+
+	if readMetaPage.rootPageId != 1<<35 {
+		t.Fatalf("Expected root page id to be %v, received %v", 1<<35, readMetaPage.rootPageId)
+	}
+}
+
+func TestDoesNotUnMarshalAnEmptyPageAsMetaPage(t *testing.T) {
+	metaPage := &MetaPage{}
+	err := metaPage.UnMarshalBinary(make([]byte, os.Getpagesize()))
+
+	if err == nil {
+		t.Fatalf("Expected an error while unmarshalling an empty page as meta page")
+	}
+}
+
+func TestAppliesTheAllowedPageOccupancyPercentageFromMetaPage(t *testing.T) {
+	options := DefaultOptions()
+	metaPage := NewMetaPage(options)
+	options.AllowedPageOccupancyPercentage = 50
+
+	appliedOptions, _ := metaPage.applyTo(options)
+	expected := DefaultOptions().AllowedPageOccupancyPercentage
+
+	if appliedOptions.AllowedPageOccupancyPercentage != expected {
+		t.Fatalf("Expected AllowedPageOccupancyPercentage to be %v, received %v", expected, appliedOptions.AllowedPageOccupancyPercentage)
+	}
+}
+
+func TestDoesNotApplyOptionsGivenPageSizeDoesNotMatchMetaPage(t *testing.T) {
+	options := DefaultOptions()
+	metaPage := NewMetaPage(options)
+	options.PageSize = options.PageSize * 2
+
+	_, err := metaPage.applyTo(options)
+	if err == nil {
+		t.Fatalf("Expected an error given PageSize does not match the meta page")
+	}
+}
+
+func TestReturnsTheOptionsStoredInTheMetaPageOfAnIndexFile(t *testing.T) {
+	options := DefaultOptions()
+	options.PageSize = 2 * os.Getpagesize()
+	options.AllowedPageOccupancyPercentage = 60
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+	_ = tree.Close()
+
+	storedOptions, err := StoredOptions(options.FileName)
+	if err != nil {
+		t.Fatalf("Expected no error while reading the stored options, received %v", err)
+	}
+	if storedOptions.PageSize != options.PageSize || storedOptions.AllowedPageOccupancyPercentage != 60 {
+		t.Fatalf("Expected PageSize %v and AllowedPageOccupancyPercentage 60, received %+v", options.PageSize, storedOptions)
+	}
+}
+
+func TestDoesNotReturnTheStoredOptionsGivenTheIndexFileDoesNotExist(t *testing.T) {
+	if _, err := StoredOptions("missing.db"); !os.IsNotExist(err) {
+		t.Fatalf("Expected a not exist error, received %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package index
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"time"
+)
 
 type Options struct {
 	// PageSize for file I/O. All reads and writes will always
@@ -17,6 +21,49 @@ type Options struct {
 	// AllowedPageOccupancyPercentage defines the amount of size that a page should occupy in bytes.
 	// After this size, page will be split
 	AllowedPageOccupancyPercentage int
+
+	// ReadOnly opens an existing index file without any write access. All the mutations return ErrReadOnly
+	ReadOnly bool
+
+	// LockTimeout is the duration to wait for the lock on the index file held by another process.
+	// Writers take an exclusive lock and read-only opens take a shared lock, ErrLocked is returned after the timeout
+	LockTimeout time.Duration
+
+	// StorageType selects the backend for page I/O, a memory mapped file by default or pread/pwrite on the file
+	StorageType StorageType
+
+	// InMemory keeps all the pages in memory without any index file. FileName and StorageType are ignored
+	// and nothing is persisted after the B+Tree is closed
+	InMemory bool
+
+	// MaxGrowthPages caps the number of pages by which the index file grows at once. The file grows geometrically,
+	// doubling its page count until this cap, so that splits do not resize the file every time. 0 grows the file
+	// only by the pages being allocated
+	MaxGrowthPages int
+
+	// MemoryMapSize is the size of the virtual mapping in bytes reserved up front for the index file. Growing the file
+	// within this size does not remap it, the reservation is doubled once the file outgrows it. Windows maps only the
+	// file, as a mapping there grows a read-write file to its size
+	MemoryMapSize int64
+
+	// KeyPrefixCompression stores the prefix shared by all the keys of a page once, the cells keep only the rest
+	// of the keys. Pages written with or without it can be read either way, so it can be changed across opens
+	KeyPrefixCompression bool
+
+	// Compression selects the codec for leaf pages, a page that does not get smaller is written uncompressed.
+	// Splits are driven by the logical (uncompressed) size of a page and each page keeps its slot in the index file,
+	// so compression cuts the bytes written per page rather than the size of the file
+	Compression Compression
+
+	// KeyProvider turns on the encryption of pages with AES-GCM, each page is sealed with a fresh nonce and records
+	// the id of its key. The meta page is not encrypted but records the id of the current key, and an index file
+	// holding encrypted pages can only be opened with a KeyProvider
+	KeyProvider KeyProvider
+
+	// SplitPolicy decides where a full leaf page is split, ByteBalancedSplit by default. Regardless of the policy,
+	// a key going past the last key of the rightmost leaf page moves only the last key value pair to the new page,
+	// so that increasing keys leave full pages behind
+	SplitPolicy SplitPolicy
 }
 
 func DefaultOptions() Options {
@@ -25,5 +72,38 @@ func DefaultOptions() Options {
 		FileName:                       "index.db",
 		PreAllocatedPagePoolSize:       10,
 		AllowedPageOccupancyPercentage: 80,
+		MaxGrowthPages:                 1024,
+		MemoryMapSize:                  64 * 1024 * 1024,
+	}
+}
+
+func (options Options) Validate() error {
+	if options.PageSize <= 0 || options.PageSize%os.Getpagesize() != 0 {
+		return fmt.Errorf("PageSize must be a positive multiple of %v, received %v", os.Getpagesize(), options.PageSize)
+	}
+	if options.InMemory && options.ReadOnly {
+		return fmt.Errorf("InMemory and ReadOnly can not be used together")
+	}
+	if len(options.FileName) == 0 && !options.InMemory {
+		return fmt.Errorf("FileName must not be empty")
+	}
+	if options.PreAllocatedPagePoolSize <= 0 {
+		return fmt.Errorf("PreAllocatedPagePoolSize must be greater than 0, received %v", options.PreAllocatedPagePoolSize)
+	}
+	if options.AllowedPageOccupancyPercentage <= 0 || options.AllowedPageOccupancyPercentage > 100 {
+		return fmt.Errorf("AllowedPageOccupancyPercentage must be between 1 and 100, received %v", options.AllowedPageOccupancyPercentage)
+	}
+	if options.MaxGrowthPages < 0 {
+		return fmt.Errorf("MaxGrowthPages must not be negative, received %v", options.MaxGrowthPages)
+	}
+	if options.MemoryMapSize < 0 {
+		return fmt.Errorf("MemoryMapSize must not be negative, received %v", options.MemoryMapSize)
+	}
+	if options.Compression > LZCompression {
+		return fmt.Errorf("Compression must be one of NoCompression, FlateCompression or LZCompression, received %v", options.Compression)
+	}
+	if options.SplitPolicy > CountBalancedSplit {
+		return fmt.Errorf("SplitPolicy must be one of ByteBalancedSplit or CountBalancedSplit, received %v", options.SplitPolicy)
 	}
+	return nil
 }
@@ -0,0 +1,95 @@
+package index
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidatesDefaultOptions(t *testing.T) {
+	err := DefaultOptions().Validate()
+	if err != nil {
+		t.Fatalf("Expected default options to be valid, received %v", err)
+	}
+}
+
+func TestDoesNotValidateOptionsGivenPageSizeIsNotAMultipleOfOSPageSize(t *testing.T) {
+	options := DefaultOptions()
+	options.PageSize = os.Getpagesize() + 100
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with PageSize %v to be invalid", options.PageSize)
+	}
+}
+
+func TestDoesNotValidateOptionsGivenPreAllocatedPagePoolSizeIsZero(t *testing.T) {
+	options := DefaultOptions()
+	options.PreAllocatedPagePoolSize = 0
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with PreAllocatedPagePoolSize 0 to be invalid")
+	}
+}
+
+func TestDoesNotValidateOptionsGivenAllowedPageOccupancyPercentageIsOutOfRange(t *testing.T) {
+	options := DefaultOptions()
+	options.AllowedPageOccupancyPercentage = 101
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with AllowedPageOccupancyPercentage 101 to be invalid")
+	}
+}
+
+func TestDoesNotValidateOptionsGivenFileNameIsEmpty(t *testing.T) {
+	options := DefaultOptions()
+	options.FileName = ""
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with empty FileName to be invalid")
+	}
+}
+
+func TestValidatesInMemoryOptionsWithoutFileName(t *testing.T) {
+	options := DefaultOptions()
+	options.FileName = ""
+	options.InMemory = true
+
+	err := options.Validate()
+	if err != nil {
+		t.Fatalf("Expected in-memory options without FileName to be valid, received %v", err)
+	}
+}
+
+func TestDoesNotValidateInMemoryOptionsInReadOnlyMode(t *testing.T) {
+	options := DefaultOptions()
+	options.InMemory = true
+	options.ReadOnly = true
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected in-memory options in read-only mode to be invalid")
+	}
+}
+
+func TestDoesNotValidateOptionsGivenAnUnknownCompression(t *testing.T) {
+	options := DefaultOptions()
+	options.Compression = Compression(10)
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with Compression %v to be invalid", options.Compression)
+	}
+}
+
+func TestDoesNotValidateOptionsGivenAnUnknownSplitPolicy(t *testing.T) {
+	options := DefaultOptions()
+	options.SplitPolicy = SplitPolicy(10)
+
+	err := options.Validate()
+	if err == nil {
+		t.Fatalf("Expected options with SplitPolicy %v to be invalid", options.SplitPolicy)
+	}
+}
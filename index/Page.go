@@ -3,19 +3,36 @@ package index
 import (
 	"b+tree/index/schema"
 	"bytes"
+	"fmt"
 	"math"
 	"sort"
 )
 
 const (
-	LeafPage    = uint8(0x0)
-	NonLeafPage = uint8(0x01)
+	LeafPage     = uint8(0x0)
+	NonLeafPage  = uint8(0x01)
+	MetaDataPage = uint8(0x02)
 )
 
 type Page struct {
 	id            int
+	level         int
+	lsn           uint64
 	keyValuePairs []KeyValuePair
 	childPageIds  []int
+	image         []byte
+	dirtyRanges   []byteRange
+	sizeInBytes   int
+
+	// compressKeyPrefix lays the page out with the prefix shared by its keys stored once. keyPrefixLength is the
+	// length of that prefix when the size was last computed.
+	compressKeyPrefix bool
+	keyPrefixLength   int
+}
+
+type persistentPage interface {
+	Size() uint64
+	Marshal(buf []byte) ([]byte, error)
 }
 
 type DirtyPage struct {
@@ -37,100 +54,143 @@ func (page Page) GetKeyValuePairAt(index int) KeyValuePair {
 }
 
 func (page Page) MarshalBinary() []byte {
-	if page.isLeaf() {
-		buffer, _ := page.toPersistentLeafPage().Marshal(nil)
-		return buffer
-	} else {
-		buffer, _ := page.toPersistentNonLeafPage().Marshal(nil)
-		return buffer
-	}
+	buffer := make([]byte, page.size())
+	page.marshalInto(buffer)
+	return buffer
 }
 
-func (page Page) toPersistentLeafPage() *schema.PersistentLeafPage {
-	persistentKeyValuePairs := make([]schema.PersistentKeyValuePair, len(page.keyValuePairs))
-	for index, keyValuePair := range page.keyValuePairs {
-		persistentKeyValuePairs[index] = keyValuePair.toPersistentKeyValuePair()
+func (page Page) header() *schema.PersistentPageHeader {
+	header := &schema.PersistentPageHeader{
+		PageType:   LeafPage,
+		Level:      uint16(page.level),
+		Lsn:        page.lsn,
+		EntryCount: uint32(len(page.keyValuePairs)),
+	}
+	if !page.isLeaf() {
+		header.PageType = NonLeafPage
 	}
-	return &schema.PersistentLeafPage{
-		PageType: LeafPage,
-		Pairs:    persistentKeyValuePairs,
+	if len(page.childPageIds) > 0 {
+		header.FirstChildPageId = uint64(page.childPageIds[0])
 	}
+	return header
 }
 
-func (page Page) toPersistentNonLeafPage() *schema.PersistentNonLeafPage {
-	persistentKeyValuePairs := make([]schema.PersistentKeyValuePair, len(page.keyValuePairs))
-	childPageIds := make([]uint32, len(page.childPageIds))
-
-	for index, keyValuePair := range page.keyValuePairs {
-		persistentKeyValuePairs[index] = keyValuePair.toPersistentKeyValuePair()
+// UnMarshalBinary decodes the page header, decompresses the page if needed and verifies the checksum before
+// decoding the cells.
+// A page that was never written has a zero header and is decoded as an empty leaf page.
+func (page *Page) UnMarshalBinary(buffer []byte) error {
+	header, err := UnMarshalPageHeader(buffer)
+	if err != nil {
+		return err
+	}
+	if header.Encryption != 0 {
+		return fmt.Errorf("page %v: %w", page.id, ErrPageEncrypted)
+	}
+	if header.FreeSpaceOffset == 0 {
+		return nil
+	}
+	if Compression(header.Codec) != NoCompression {
+		if buffer, err = decompressPage(buffer, header); err != nil {
+			return fmt.Errorf("page %v could not be decompressed: %w", page.id, err)
+		}
+	}
+	if err := validateLayout(buffer, header); err != nil {
+		return fmt.Errorf("page %v has an invalid layout: %w", page.id, err)
 	}
-	for index, childPageId := range page.childPageIds {
-		childPageIds[index] = uint32(childPageId)
+	checksum, err := checksumOf(buffer, header)
+	if err != nil {
+		return fmt.Errorf("page %v has an invalid layout: %w", page.id, err)
 	}
-	return &schema.PersistentNonLeafPage{
-		PageType:     NonLeafPage,
-		Pairs:        persistentKeyValuePairs,
-		ChildPageIds: childPageIds,
+	if checksum != header.Checksum {
+		return fmt.Errorf("page %v failed the checksum verification", page.id)
 	}
+	page.level = int(header.Level)
+	page.lsn = header.Lsn
+	return page.unMarshalCells(buffer, header)
 }
 
-func (page *Page) UnMarshalBinary(buffer []byte) {
-	if buffer[0]&NonLeafPage == 0 {
-		persistentLeafPage := schema.PersistentLeafPage{}
-		_, _ = persistentLeafPage.Unmarshal(buffer)
+// UnMarshalPageHeader decodes only the fixed size header at the beginning of a page.
+func UnMarshalPageHeader(buffer []byte) (*schema.PersistentPageHeader, error) {
+	header := &schema.PersistentPageHeader{}
+	if len(buffer) < int(header.Size()) {
+		return nil, fmt.Errorf("buffer of %v bytes is too small for a page header", len(buffer))
+	}
+	_, _ = header.Unmarshal(buffer)
+	return header, nil
+}
 
-		for _, persistentKeyValuePair := range persistentLeafPage.Pairs {
-			page.keyValuePairs = append(
-				page.keyValuePairs,
-				KeyValuePair{
-					key: persistentKeyValuePair.Key, value: persistentKeyValuePair.Value,
-				},
-			)
-		}
-	} else {
-		persistentNonLeafPage := schema.PersistentNonLeafPage{}
-		_, _ = persistentNonLeafPage.Unmarshal(buffer)
-
-		for _, persistentKeyValuePair := range persistentNonLeafPage.Pairs {
-			page.keyValuePairs = append(
-				page.keyValuePairs,
-				KeyValuePair{
-					key: persistentKeyValuePair.Key, value: persistentKeyValuePair.Value,
-				},
-			)
-		}
-		for _, persistentChildPageId := range persistentNonLeafPage.ChildPageIds {
-			page.childPageIds = append(
-				page.childPageIds,
-				int(persistentChildPageId),
-			)
-		}
+// size returns the number of bytes the page takes once laid out. It is computed on the first check after a
+// structural change and kept up to date by insertAt and updateAt, which makes split checks O(1).
+func (page *Page) size() int {
+	if page.sizeInBytes == 0 {
+		page.sizeInBytes, page.keyPrefixLength = page.layoutSize()
 	}
+	return page.sizeInBytes
 }
 
-func (page Page) size() int {
-	if page.isLeaf() {
-		return int(page.toPersistentLeafPage().Size())
-	} else {
-		return int(page.toPersistentNonLeafPage().Size())
+// layoutSize computes the number of bytes the page takes once laid out, along with the length of the shared key
+// prefix, without keeping them. It is safe on a cached page that the readers share.
+func (page Page) layoutSize() (int, int) {
+	keyPrefixLength := page.sharedKeyPrefixLength()
+	size := pageHeaderSize + keyPrefixLength + len(page.keyValuePairs)*slotSize
+	for index := range page.keyValuePairs {
+		size = size + int(page.cellAt(index, keyPrefixLength).Size())
 	}
+	return size, keyPrefixLength
 }
 
+// binarySearch compares the key against the prefix shared by all the keys of the page once, and then compares
+// only the rest of the keys while searching.
 func (page Page) binarySearch(key []byte) (int, bool) {
+	prefixLength := commonPrefixLength(page.keyValuePairs)
+	if prefixLength > 0 {
+		prefix := page.keyValuePairs[0].key[:prefixLength]
+		if !bytes.HasPrefix(key, prefix) {
+			if bytes.Compare(key, prefix) < 0 {
+				return 0, false
+			}
+			return len(page.keyValuePairs), false
+		}
+	}
+	suffix := key[prefixLength:]
 	index := sort.Search(len(page.keyValuePairs), func(index int) bool {
-		if bytes.Compare(key, page.keyValuePairs[index].key) < 0 {
+		if bytes.Compare(suffix, page.keyValuePairs[index].key[prefixLength:]) < 0 {
 			return true
 		}
 		return false
 	})
-	if index > 0 && bytes.Compare(page.keyValuePairs[index-1].key, key) == 0 {
+	if index > 0 && bytes.Equal(page.keyValuePairs[index-1].key[prefixLength:], suffix) {
 		return index - 1, true
 	}
 	return index, false
 }
 
+// sharedKeyPrefixLength returns the length of the prefix that is stored once when the page is laid out.
+func (page Page) sharedKeyPrefixLength() int {
+	if !page.compressKeyPrefix {
+		return 0
+	}
+	return commonPrefixLength(page.keyValuePairs)
+}
+
+// commonPrefixLength returns the length of the prefix shared by sorted key value pairs, which is the prefix
+// shared by the first and the last key.
+func commonPrefixLength(keyValuePairs []KeyValuePair) int {
+	if len(keyValuePairs) < 2 {
+		return 0
+	}
+	first, last := keyValuePairs[0].key, keyValuePairs[len(keyValuePairs)-1].key
+	length := 0
+	for length < len(first) && length < len(last) && first[length] == last[length] {
+		length++
+	}
+	return length
+}
+
+// isLeaf tells the leaf pages, at level 0, from the non-leaf pages above them. A non-leaf page left without child
+// pages is still a non-leaf page.
 func (page Page) isLeaf() bool {
-	return len(page.childPageIds) == 0
+	return page.level == 0
 }
 
 func (page *Page) insertAt(index int, keyValuePair KeyValuePair) DirtyPage {
@@ -142,32 +202,84 @@ func (page *Page) insertAt(index int, keyValuePair KeyValuePair) DirtyPage {
 	} else {
 		page.keyValuePairs[index] = KeyValuePair{key: keyValuePair.key}
 	}
+	if page.sizeInBytes != 0 {
+		if keyPrefixLength := page.sharedKeyPrefixLength(); keyPrefixLength == page.keyPrefixLength {
+			page.sizeInBytes = page.sizeInBytes + slotSize + int(page.cellAt(index, keyPrefixLength).Size())
+		} else {
+			page.sizeInBytes = 0
+		}
+	}
+	if page.isLeaf() {
+		page.insertCellAt(index)
+	} else {
+		page.dropImage()
+	}
 	return DirtyPage{page: page}
 }
 
 func (page *Page) updateAt(index int, keyValuePair KeyValuePair) DirtyPage {
+	existingCellSize := int(page.cellAt(index, page.keyPrefixLength).Size())
 	page.keyValuePairs[index] = keyValuePair
+	if page.sizeInBytes != 0 {
+		page.sizeInBytes = page.sizeInBytes - existingCellSize + int(page.cellAt(index, page.keyPrefixLength).Size())
+	}
+	page.updateCellAt(index)
+	return DirtyPage{page: page}
+}
+
+func (page *Page) deleteRange(fromIndex, toIndex int) DirtyPage {
+	page.keyValuePairs = append(page.keyValuePairs[:fromIndex], page.keyValuePairs[toIndex:]...)
+	page.resetLayout()
 	return DirtyPage{page: page}
 }
 
+func (page *Page) deleteChildrenRange(fromIndex, toIndex int) DirtyPage {
+	page.childPageIds = append(page.childPageIds[:fromIndex], page.childPageIds[toIndex:]...)
+	page.resetLayout()
+	if fromIndex == 0 {
+		return page.deleteRange(0, toIndex)
+	}
+	return page.deleteRange(fromIndex-1, toIndex-1)
+}
+
+func (page *Page) merge(parentPage *Page, siblingPage *Page, index int) []DirtyPage {
+	if !page.isLeaf() {
+		page.keyValuePairs = append(page.keyValuePairs, parentPage.keyValuePairs[index])
+	}
+	page.keyValuePairs = append(page.keyValuePairs, siblingPage.keyValuePairs...)
+	page.childPageIds = append(page.childPageIds, siblingPage.childPageIds...)
+	page.resetLayout()
+
+	parentPage.childPageIds = append(parentPage.childPageIds[:index+1], parentPage.childPageIds[index+2:]...)
+	return []DirtyPage{{page: page}, parentPage.deleteRange(index, index+1)}
+}
+
 func (page *Page) insertChildAt(index int, childPage *Page) DirtyPage {
 	page.childPageIds = append(page.childPageIds, 0)
 	copy(page.childPageIds[index+1:], page.childPageIds[index:])
 	page.childPageIds[index] = childPage.id
+	page.resetLayout()
 
 	return DirtyPage{page: page}
 }
 
-func (page *Page) split(parentPage *Page, siblingPage *Page, index int) ([]DirtyPage, error) {
+func (page *Page) split(parentPage *Page, siblingPage *Page, index int, splitPolicy SplitPolicy) ([]DirtyPage, error) {
 	dirtyPages := []DirtyPage{{page: page}, {page: siblingPage}, {page: parentPage}}
+	siblingPage.level = page.level
 
 	if page.isLeaf() {
-		pageKeyValuePairs := page.AllKeyValuePairs()
-		siblingPage.keyValuePairs = append(siblingPage.keyValuePairs, page.keyValuePairs[len(pageKeyValuePairs)/2:]...)
-		page.keyValuePairs = page.keyValuePairs[:len(pageKeyValuePairs)/2]
+		splitIndex := splitPolicy.splitIndex(page.keyValuePairs)
+		siblingPage.keyValuePairs = append(siblingPage.keyValuePairs, page.keyValuePairs[splitIndex:]...)
+		page.keyValuePairs = page.keyValuePairs[:splitIndex]
+		page.resetLayout()
+		siblingPage.resetLayout()
 
+		separator := siblingPage.keyValuePairs[0].key
+		if len(page.keyValuePairs) > 0 {
+			separator = shortestSeparator(page.keyValuePairs[len(page.keyValuePairs)-1].key, separator)
+		}
 		dirtyPages = append(dirtyPages, parentPage.insertChildAt(index+1, siblingPage))
-		dirtyPages = append(dirtyPages, parentPage.insertAt(index, siblingPage.keyValuePairs[0]))
+		dirtyPages = append(dirtyPages, parentPage.insertAt(index, KeyValuePair{key: separator}))
 	} else {
 		parentKey := page.keyValuePairs[len(page.AllKeyValuePairs())/2]
 
@@ -181,6 +293,8 @@ func (page *Page) split(parentPage *Page, siblingPage *Page, index int) ([]Dirty
 			siblingPage.childPageIds = append(siblingPage.childPageIds, page.childPageIds[:len(page.childPageIds)/2]...)
 			page.childPageIds = page.childPageIds[len(page.childPageIds)/2:]
 		}
+		page.resetLayout()
+		siblingPage.resetLayout()
 
 		dirtyPages = append(dirtyPages, parentPage.insertChildAt(index, siblingPage))
 		dirtyPages = append(dirtyPages, parentPage.insertAt(index, parentKey))
@@ -188,6 +302,20 @@ func (page *Page) split(parentPage *Page, siblingPage *Page, index int) ([]Dirty
 	return dirtyPages, nil
 }
 
+// shortestSeparator returns the shortest key that is greater than the last key of the left page and less than or
+// equal to the first key of the right page, which is the first key of the right page cut right after the first
+// byte that differs from the last key of the left page.
+func shortestSeparator(lastLeftKey, firstRightKey []byte) []byte {
+	length := 0
+	for length < len(lastLeftKey) && length < len(firstRightKey) && lastLeftKey[length] == firstRightKey[length] {
+		length++
+	}
+	if length < len(firstRightKey) {
+		length++
+	}
+	return append([]byte(nil), firstRightKey[:length]...)
+}
+
 func (page *Page) AllKeyValuePairs() []KeyValuePair {
 	return page.keyValuePairs
 }
@@ -2,15 +2,34 @@ package index
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 )
 
+type UpdateFunc func(oldValue []byte, exists bool) ([]byte, bool)
+
+var ErrKeyTooLarge = errors.New("key exceeds the maximum key size")
+
+// nonLeafCellOverhead is the most a non-leaf cell takes beyond its key: the slot, the varint key length and the
+// varint child page id.
+const nonLeafCellOverhead = slotSize + binary.MaxVarintLen32 + binary.MaxVarintLen64
+
 type PageHierarchy struct {
 	rootPage                       *Page
 	pageById                       map[int]*Page
 	pagePool                       *PagePool
 	allowedPageOccupancyPercentage int
 	freePageList                   *FreePageList
+	splitPolicy                    SplitPolicy
+
+	// allocatedPageIds are the pages allocated by the running Update, released again if it fails
+	allocatedPageIds []int
+
+	// pageByIdLock guards pageById against the readers that cache pages concurrently under the read lock of the tree
+	pageByIdLock *sync.Mutex
 }
 
 func NewPageHierarchy(pagePool *PagePool, allowedPageOccupancyPercentage int, freePageList *FreePageList) *PageHierarchy {
@@ -20,56 +39,255 @@ func NewPageHierarchy(pagePool *PagePool, allowedPageOccupancyPercentage int, fr
 		pageById:                       map[int]*Page{},
 		allowedPageOccupancyPercentage: allowedPageOccupancyPercentage,
 		freePageList:                   freePageList,
+		pageByIdLock:                   &sync.Mutex{},
 	}
 	pageHierarchy.pageById[pageHierarchy.rootPage.id] = pageHierarchy.rootPage
 	return pageHierarchy
 }
 
+func (pageHierarchy *PageHierarchy) loadRootPage(rootPageId int) error {
+	rootPage, err := pageHierarchy.pagePool.Read(rootPageId)
+	if err != nil {
+		return err
+	}
+	delete(pageHierarchy.pageById, pageHierarchy.rootPage.id)
+	pageHierarchy.rootPage = rootPage
+	pageHierarchy.pageById[rootPage.id] = rootPage
+	return nil
+}
+
 func (pageHierarchy *PageHierarchy) Put(keyValuePair KeyValuePair) error {
+	_, err := pageHierarchy.Update(keyValuePair.key, func(oldValue []byte, exists bool) ([]byte, bool) {
+		return keyValuePair.value, true
+	})
+	return err
+}
+
+// Update applies the update to the key. A failed update discards its changes to the cached pages, so that a pair
+// that could not be written is not left behind in memory.
+func (pageHierarchy *PageHierarchy) Update(key []byte, update UpdateFunc) (bool, error) {
+	rootPageId := pageHierarchy.rootPage.id
+	pageHierarchy.allocatedPageIds = nil
+	updated, err := pageHierarchy.update(key, update)
+	if err != nil {
+		if discardErr := pageHierarchy.discardChanges(rootPageId); discardErr != nil {
+			return false, fmt.Errorf("%w, discarding the changes failed: %v", err, discardErr)
+		}
+		return false, err
+	}
+	return updated, nil
+}
+
+func (pageHierarchy *PageHierarchy) update(key []byte, update UpdateFunc) (bool, error) {
+	if len(key) > pageHierarchy.maxKeySize() {
+		return false, fmt.Errorf("key of %v bytes, the maximum is %v: %w", len(key), pageHierarchy.maxKeySize(), ErrKeyTooLarge)
+	}
 
-	splitRoot := func() ([]DirtyPage, error) {
+	splitRoot := func(splitPolicy SplitPolicy) ([]DirtyPage, error) {
 		siblingPageCount := 1
 		newRootPageCount := 1
 
 		pages, err := pageHierarchy.allocatePages(siblingPageCount + newRootPageCount)
 		if err != nil {
-			return []DirtyPage{}, nil
+			return []DirtyPage{}, err
 		}
 		newRootPage, rightSiblingPage, oldRootPage := pages[0], pages[1], pageHierarchy.rootPage
 		newRootPage.childPageIds = append(newRootPage.childPageIds, oldRootPage.id)
+		newRootPage.level = oldRootPage.level + 1
 		pageHierarchy.rootPage = newRootPage
 
-		return oldRootPage.split(newRootPage, rightSiblingPage, 0)
+		return oldRootPage.split(newRootPage, rightSiblingPage, 0, splitPolicy)
 	}
 
 	var dirtyPages []DirtyPage
-	if pageHierarchy.isPageEligibleForSplit(pageHierarchy.rootPage) {
-		rootSplitDirtyPages, err := splitRoot()
+	if pageHierarchy.isNonLeafEligibleForSplit(pageHierarchy.rootPage) {
+		rootSplitDirtyPages, err := splitRoot(pageHierarchy.splitPolicy)
 		if err != nil {
-			return err
+			return false, err
 		}
 		dirtyPages = append(dirtyPages, rootSplitDirtyPages...)
 	}
-	dirtyPages, err := pageHierarchy.put(keyValuePair, pageHierarchy.rootPage, dirtyPages)
+	rootPage := pageHierarchy.rootPage
+	splitPolicy := pageHierarchy.splitPolicyFor(rootPage, key, true)
+	dirtyPages, updated, err := pageHierarchy.put(key, update, rootPage, true, dirtyPages)
 	if err != nil {
-		return err
+		return false, err
 	}
-	pageHierarchy.Write(dirtyPages)
-	return nil
+	if pageHierarchy.isLeafEligibleForSplit(rootPage) {
+		splitPolicy, err := pageHierarchy.leafSplitPolicy(rootPage, splitPolicy)
+		if err != nil {
+			return false, err
+		}
+		rootSplitDirtyPages, err := splitRoot(splitPolicy)
+		if err != nil {
+			return false, err
+		}
+		dirtyPages = append(dirtyPages, rootSplitDirtyPages...)
+	}
+	if err := pageHierarchy.Write(dirtyPages); err != nil {
+		return false, err
+	}
+	return updated, nil
 }
 
 func (pageHierarchy *PageHierarchy) Get(key []byte) GetResult {
 	return pageHierarchy.get(key, pageHierarchy.rootPage)
 }
 
-func (pageHierarchy *PageHierarchy) Write(dirtyPages []DirtyPage) {
+func (pageHierarchy *PageHierarchy) MultiGet(keys [][]byte) []GetResult {
+	positions := make([]int, len(keys))
+	for index := range positions {
+		positions[index] = index
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return bytes.Compare(keys[positions[i]], keys[positions[j]]) < 0
+	})
+	getResults := make([]GetResult, len(keys))
+	pageHierarchy.multiGet(keys, positions, pageHierarchy.rootPage, getResults)
+	return getResults
+}
+
+// DeleteRange deletes all the keys in the range [start, end), a nil end runs till the last key. Subtrees that lie
+// entirely inside the range are released to the FreePageList without reading their leaf pages, and only the two
+// boundary paths are rebalanced.
+func (pageHierarchy *PageHierarchy) DeleteRange(start, end []byte) error {
+	if end != nil && bytes.Compare(start, end) >= 0 {
+		return nil
+	}
+	height, err := pageHierarchy.height()
+	if err != nil {
+		return err
+	}
+	if len(start) == 0 && end == nil {
+		return pageHierarchy.deleteAll(height)
+	}
+	dirtyPages, err := pageHierarchy.deleteRange(start, end, pageHierarchy.rootPage, nil, nil, height)
+	if err != nil {
+		return err
+	}
+	for !pageHierarchy.rootPage.isLeaf() && len(pageHierarchy.rootPage.keyValuePairs) == 0 {
+		newRootPage, err := pageHierarchy.fetchOrCachePage(pageHierarchy.rootPage.childPageIds[0])
+		if err != nil {
+			return err
+		}
+		pageHierarchy.release([]int{pageHierarchy.rootPage.id})
+		pageHierarchy.rootPage = newRootPage
+	}
+	return pageHierarchy.Write(dirtyPages)
+}
+
+// Compact moves the live pages from the end of the file into the free slots near its start, rewrites the
+// childPageIds of their parents and truncates the file after the last live page.
+func (pageHierarchy *PageHierarchy) Compact() error {
+	height, err := pageHierarchy.height()
+	if err != nil {
+		return err
+	}
+	livePageIds, err := pageHierarchy.subtreePageIds(pageHierarchy.rootPage.id, height)
+	if err != nil {
+		return err
+	}
+	pageCount := metaPageCount + len(livePageIds)
+	isLive := make(map[int]bool)
+	for _, pageId := range livePageIds {
+		isLive[pageId] = true
+	}
+	var freeSlots []int
+	for pageId := metaPageCount; pageId < pageCount; pageId++ {
+		if !isLive[pageId] {
+			freeSlots = append(freeSlots, pageId)
+		}
+	}
+
+	newPageIdByPageId := make(map[int]int)
+	for _, pageId := range livePageIds {
+		if pageId >= pageCount {
+			newPageIdByPageId[pageId] = freeSlots[0]
+			freeSlots = freeSlots[1:]
+		}
+	}
+
+	var dirtyPages []DirtyPage
+	for _, pageId := range livePageIds {
+		newPageId, moved := newPageIdByPageId[pageId]
+		if !moved && !pageHierarchy.isCachedNonLeaf(pageId) {
+			continue
+		}
+		page, err := pageHierarchy.fetchOrCachePage(pageId)
+		if err != nil {
+			return err
+		}
+		childPagesMoved := false
+		for index, childPageId := range page.childPageIds {
+			if newChildPageId, childMoved := newPageIdByPageId[childPageId]; childMoved {
+				page.childPageIds[index] = newChildPageId
+				childPagesMoved = true
+			}
+		}
+		if moved {
+			delete(pageHierarchy.pageById, pageId)
+			page.id = newPageId
+			pageHierarchy.pageById[newPageId] = page
+		}
+		if moved || childPagesMoved {
+			page.resetLayout()
+			dirtyPages = append(dirtyPages, DirtyPage{page: page})
+		}
+	}
+	if err := pageHierarchy.Write(dirtyPages); err != nil {
+		return err
+	}
+	for pageId := range pageHierarchy.pageById {
+		if pageId >= pageCount {
+			delete(pageHierarchy.pageById, pageId)
+		}
+	}
+	pageHierarchy.freePageList.pageIds = nil
+	return pageHierarchy.pagePool.Truncate(pageCount)
+}
+
+// rebuildFreePageList lists the allocated pages that are not reachable from the root page as free, the free page
+// list is not stored in the index file. Only the non-leaf pages are read, the leaf pages are known from their parents.
+func (pageHierarchy *PageHierarchy) rebuildFreePageList() error {
+	height, err := pageHierarchy.height()
+	if err != nil {
+		return err
+	}
+	livePageIds, err := pageHierarchy.subtreePageIds(pageHierarchy.rootPage.id, height)
+	if err != nil {
+		return err
+	}
+	isLive := make(map[int]bool)
+	for _, pageId := range livePageIds {
+		isLive[pageId] = true
+	}
+	pageHierarchy.freePageList.pageIds = nil
+	for pageId := metaPageCount; pageId < pageHierarchy.pagePool.pageCount; pageId++ {
+		if !isLive[pageId] {
+			pageHierarchy.freePageList.pageIds = append(pageHierarchy.freePageList.pageIds, pageId)
+		}
+	}
+	return nil
+}
+
+// Write checks that all the dirty pages fit in a page before writing any of them, so that a change failing the check
+// leaves the index file untouched.
+func (pageHierarchy *PageHierarchy) Write(dirtyPages []DirtyPage) error {
+	for _, dirtyPage := range dirtyPages {
+		if size := dirtyPage.page.size(); size > pageHierarchy.pagePool.pageSize {
+			return fmt.Errorf("page %v of %v bytes does not fit in the page size %v", dirtyPage.page.id, size, pageHierarchy.pagePool.pageSize)
+		}
+	}
 	writtenPageById := make(map[int]*Page)
 	for _, dirtyPage := range dirtyPages {
 		if writtenPageById[dirtyPage.page.id] == nil {
-			pageHierarchy.pagePool.Write(dirtyPage.page)
+			if err := pageHierarchy.pagePool.Write(dirtyPage.page); err != nil {
+				return err
+			}
 			writtenPageById[dirtyPage.page.id] = dirtyPage.page
 		}
 	}
+	return nil
 }
 
 func (pageHierarchy PageHierarchy) RootPageId() int {
@@ -77,92 +295,427 @@ func (pageHierarchy PageHierarchy) RootPageId() int {
 }
 
 func (pageHierarchy PageHierarchy) PageById(id int) *Page {
+	pageHierarchy.pageByIdLock.Lock()
+	defer pageHierarchy.pageByIdLock.Unlock()
 	return pageHierarchy.pageById[id]
 }
 
-func (pageHierarchy *PageHierarchy) put(keyValuePair KeyValuePair, page *Page, dirtyPages []DirtyPage) ([]DirtyPage, error) {
+// put descends from the page towards the leaf holding the key, rightmost tells whether the page is the last one on
+// its level. The leaf page takes the key value pair first and is split afterwards by its parent, so that the split
+// accounts for the size of the pair.
+func (pageHierarchy *PageHierarchy) put(key []byte, update UpdateFunc, page *Page, rightmost bool, dirtyPages []DirtyPage) ([]DirtyPage, bool, error) {
 	if page.isLeaf() {
-		index, found := page.Get(keyValuePair.key)
+		index, found := page.Get(key)
+		var oldValue []byte
+		if found {
+			oldValue = page.GetKeyValuePairAt(index).value
+		}
+		value, ok := update(oldValue, found)
+		if !ok {
+			return dirtyPages, false, nil
+		}
+		keyValuePair := KeyValuePair{key: key, value: value}
+		persistentKeyValuePair := keyValuePair.toPersistentKeyValuePair()
+		if size := pageHeaderSize + slotSize + int(persistentKeyValuePair.Size()); size > pageHierarchy.pagePool.pageSize {
+			return dirtyPages, false, fmt.Errorf("key value pair of %v bytes does not fit in the page size %v", size, pageHierarchy.pagePool.pageSize)
+		}
 		if found {
 			dirtyPages = append(dirtyPages, page.updateAt(index, keyValuePair))
-			return dirtyPages, nil
+			return dirtyPages, true, nil
 		}
 		dirtyPages = append(dirtyPages, page.insertAt(index, keyValuePair))
-		return dirtyPages, nil
+		return dirtyPages, true, nil
 	}
-	return pageHierarchy.insertOrSplit(keyValuePair, page, dirtyPages)
+	return pageHierarchy.insertOrSplit(key, update, page, rightmost, dirtyPages)
 }
 
-func (pageHierarchy *PageHierarchy) insertOrSplit(keyValuePair KeyValuePair, page *Page, dirtyPages []DirtyPage) ([]DirtyPage, error) {
-	index, found := page.Get(keyValuePair.key)
+func (pageHierarchy *PageHierarchy) insertOrSplit(key []byte, update UpdateFunc, page *Page, rightmost bool, dirtyPages []DirtyPage) ([]DirtyPage, bool, error) {
+	index, found := page.Get(key)
 	if found {
 		index = index + 1
 	}
 
 	childPage, err := pageHierarchy.fetchOrCachePage(page.childPageIds[index])
 	if err != nil {
-		return []DirtyPage{}, nil
+		return []DirtyPage{}, false, err
 	}
 	var localDirtyPages []DirtyPage
-	if pageHierarchy.isPageEligibleForSplit(childPage) {
+	if pageHierarchy.isNonLeafEligibleForSplit(childPage) {
 		sibling, err := pageHierarchy.allocateSinglePage()
 		if err != nil {
-			return []DirtyPage{}, nil
+			return []DirtyPage{}, false, err
 		}
-		localDirtyPages, err = childPage.split(page, sibling, index)
+		localDirtyPages, err = childPage.split(page, sibling, index, pageHierarchy.splitPolicy)
 		if err != nil {
-			return []DirtyPage{}, nil
+			return []DirtyPage{}, false, err
 		}
-		if bytes.Compare(keyValuePair.key, page.keyValuePairs[index].key) >= 0 {
-			childPage, err = pageHierarchy.fetchOrCachePage(page.childPageIds[index+1])
-			if err != nil {
-				return []DirtyPage{}, nil
-			}
+		// the split moves the lower half of a non-leaf page into the sibling at index, so the child is selected
+		// again on either side of the separator
+		if bytes.Compare(key, page.keyValuePairs[index].key) >= 0 {
+			index = index + 1
+		}
+		childPage, err = pageHierarchy.fetchOrCachePage(page.childPageIds[index])
+		if err != nil {
+			return []DirtyPage{}, false, err
 		}
 	}
-	return pageHierarchy.put(keyValuePair, childPage, append(dirtyPages, localDirtyPages...))
+	childRightmost := rightmost && index == len(page.childPageIds)-1
+	splitPolicy := pageHierarchy.splitPolicyFor(childPage, key, childRightmost)
+	dirtyPages, updated, err := pageHierarchy.put(key, update, childPage, childRightmost, append(dirtyPages, localDirtyPages...))
+	if err != nil || !pageHierarchy.isLeafEligibleForSplit(childPage) {
+		return dirtyPages, updated, err
+	}
+	splitPolicy, err = pageHierarchy.leafSplitPolicy(childPage, splitPolicy)
+	if err != nil {
+		return []DirtyPage{}, false, err
+	}
+	sibling, err := pageHierarchy.allocateSinglePage()
+	if err != nil {
+		return []DirtyPage{}, false, err
+	}
+	splitDirtyPages, err := childPage.split(page, sibling, index, splitPolicy)
+	if err != nil {
+		return []DirtyPage{}, false, err
+	}
+	return append(dirtyPages, splitDirtyPages...), updated, nil
+}
+
+// splitPolicyFor returns appendSplit for the rightmost leaf page when the key goes past its last key, so that
+// increasing keys leave full pages behind instead of half full ones.
+func (pageHierarchy PageHierarchy) splitPolicyFor(page *Page, key []byte, rightmost bool) SplitPolicy {
+	if rightmost && page.isLeaf() && len(page.keyValuePairs) > 0 &&
+		bytes.Compare(key, page.keyValuePairs[len(page.keyValuePairs)-1].key) > 0 {
+		return appendSplit
+	}
+	return pageHierarchy.splitPolicy
 }
 
 func (pageHierarchy *PageHierarchy) get(key []byte, page *Page) GetResult {
 	index, found := page.Get(key)
 	if page.isLeaf() {
 		if found {
-			//Assignment:B+TreeGet:1:Get the KeyValue pair to be put inside NewKeyAvailableGetResult
-			return NewKeyAvailableGetResult(KeyValuePair{}, index, page)
+			return NewKeyAvailableGetResult(page.GetKeyValuePairAt(index), index, page)
 		}
 		return NewKeyMissingGetResult(index, page)
 	} else {
 		childPageIndex := index
 		if found {
-			//Assignment:B+TreeGet:2:Adjust the value of childPageIndex
+			childPageIndex = childPageIndex + 1
 		}
 		childPage, err := pageHierarchy.fetchOrCachePage(page.childPageIds[childPageIndex])
 		if err != nil {
 			return NewFailedGetResult(err)
 		}
-		//Assignment:B+TreeGet:3:Make a recursive call on the child page & remove fmt.Println
-		fmt.Println(childPage)
-		return GetResult{}
+		return pageHierarchy.get(key, childPage)
 	}
 }
 
-func (pageHierarchy *PageHierarchy) fetchOrCachePage(pageId int) (*Page, error) {
+func (pageHierarchy *PageHierarchy) multiGet(keys [][]byte, sortedPositions []int, page *Page, getResults []GetResult) {
+	if page.isLeaf() {
+		for _, position := range sortedPositions {
+			getResults[position] = pageHierarchy.get(keys[position], page)
+		}
+		return
+	}
+	for start := 0; start < len(sortedPositions); {
+		childPageIndex, found := page.Get(keys[sortedPositions[start]])
+		if found {
+			childPageIndex = childPageIndex + 1
+		}
+		end := start + 1
+		for ; end < len(sortedPositions); end++ {
+			index, found := page.Get(keys[sortedPositions[end]])
+			if found {
+				index = index + 1
+			}
+			if index != childPageIndex {
+				break
+			}
+		}
+		childPage, err := pageHierarchy.fetchOrCachePage(page.childPageIds[childPageIndex])
+		if err != nil {
+			for _, position := range sortedPositions[start:end] {
+				getResults[position] = NewFailedGetResult(err)
+			}
+		} else {
+			pageHierarchy.multiGet(keys, sortedPositions[start:end], childPage, getResults)
+		}
+		start = end
+	}
+}
+
+// deleteAll releases all the pages below the root page and leaves the root page an empty leaf page.
+func (pageHierarchy *PageHierarchy) deleteAll(height int) error {
+	pageIds, err := pageHierarchy.subtreePageIds(pageHierarchy.rootPage.id, height)
+	if err != nil {
+		return err
+	}
+	pageHierarchy.release(pageIds[1:])
+	rootPage := pageHierarchy.rootPage
+	rootPage.level = 0
+	rootPage.keyValuePairs = nil
+	rootPage.childPageIds = nil
+	rootPage.resetLayout()
+	return pageHierarchy.Write([]DirtyPage{{page: rootPage}})
+}
+
+func (pageHierarchy *PageHierarchy) deleteRange(start, end []byte, page *Page, lowerKey, upperKey []byte, level int) ([]DirtyPage, error) {
+	if page.isLeaf() {
+		fromIndex, _ := page.Get(start)
+		toIndex := len(page.keyValuePairs)
+		if end != nil {
+			toIndex, _ = page.Get(end)
+		}
+		if fromIndex >= toIndex {
+			return nil, nil
+		}
+		return []DirtyPage{page.deleteRange(fromIndex, toIndex)}, nil
+	}
+
+	var dirtyPages []DirtyPage
+	var boundaryPageIds []int
+	fromIndex, toIndex := -1, -1
+
+	for index, childPageId := range page.childPageIds {
+		childLowerKey, childUpperKey := lowerKey, upperKey
+		if index > 0 {
+			childLowerKey = page.keyValuePairs[index-1].key
+		}
+		if index < len(page.keyValuePairs) {
+			childUpperKey = page.keyValuePairs[index].key
+		}
+		if (childUpperKey != nil && bytes.Compare(childUpperKey, start) <= 0) || (end != nil && childLowerKey != nil && bytes.Compare(childLowerKey, end) >= 0) {
+			continue
+		}
+		if (childLowerKey != nil || len(start) == 0) && bytes.Compare(childLowerKey, start) >= 0 && (end == nil || childUpperKey != nil && bytes.Compare(childUpperKey, end) <= 0) {
+			if fromIndex == -1 {
+				fromIndex = index
+			}
+			toIndex = index + 1
+			continue
+		}
+		childPage, err := pageHierarchy.fetchOrCachePage(childPageId)
+		if err != nil {
+			return nil, err
+		}
+		childDirtyPages, err := pageHierarchy.deleteRange(start, end, childPage, childLowerKey, childUpperKey, level-1)
+		if err != nil {
+			return nil, err
+		}
+		dirtyPages = append(dirtyPages, childDirtyPages...)
+		boundaryPageIds = append(boundaryPageIds, childPageId)
+	}
+
+	if fromIndex != -1 {
+		var releasedPageIds []int
+		for _, childPageId := range page.childPageIds[fromIndex:toIndex] {
+			subtreePageIds, err := pageHierarchy.subtreePageIds(childPageId, level-1)
+			if err != nil {
+				return nil, err
+			}
+			releasedPageIds = append(releasedPageIds, subtreePageIds...)
+		}
+		dirtyPages = append(dirtyPages, page.deleteChildrenRange(fromIndex, toIndex))
+		pageHierarchy.release(releasedPageIds)
+	}
+	for _, boundaryPageId := range boundaryPageIds {
+		mergeDirtyPages, err := pageHierarchy.mergeIfUnderflow(page, boundaryPageId)
+		if err != nil {
+			return nil, err
+		}
+		dirtyPages = append(dirtyPages, mergeDirtyPages...)
+	}
+	return dirtyPages, nil
+}
+
+func (pageHierarchy *PageHierarchy) mergeIfUnderflow(parentPage *Page, childPageId int) ([]DirtyPage, error) {
+	index := -1
+	for childIndex, id := range parentPage.childPageIds {
+		if id == childPageId {
+			index = childIndex
+			break
+		}
+	}
+	if index == -1 || len(parentPage.childPageIds) < 2 {
+		return nil, nil
+	}
+	childPage, err := pageHierarchy.fetchOrCachePage(childPageId)
+	if err != nil {
+		return nil, err
+	}
+	if !pageHierarchy.isPageUnderflow(childPage) {
+		return nil, nil
+	}
+	if index == len(parentPage.childPageIds)-1 {
+		index = index - 1
+	}
+	leftPage, err := pageHierarchy.fetchOrCachePage(parentPage.childPageIds[index])
+	if err != nil {
+		return nil, err
+	}
+	rightPage, err := pageHierarchy.fetchOrCachePage(parentPage.childPageIds[index+1])
+	if err != nil {
+		return nil, err
+	}
+	mergedPage := &Page{
+		level:         leftPage.level,
+		keyValuePairs: append(append([]KeyValuePair(nil), leftPage.keyValuePairs...), rightPage.keyValuePairs...),
+		childPageIds:  append(append([]int(nil), leftPage.childPageIds...), rightPage.childPageIds...),
+	}
+	if !mergedPage.isLeaf() {
+		mergedPage.keyValuePairs = append(mergedPage.keyValuePairs, parentPage.keyValuePairs[index])
+	}
+	if pageHierarchy.isPageEligibleForSplit(mergedPage) {
+		return nil, nil
+	}
+	dirtyPages := leftPage.merge(parentPage, rightPage, index)
+	pageHierarchy.release([]int{rightPage.id})
+	return dirtyPages, nil
+}
+
+func (pageHierarchy *PageHierarchy) subtreePageIds(pageId int, level int) ([]int, error) {
+	if level <= 1 {
+		return []int{pageId}, nil
+	}
+	page, err := pageHierarchy.fetchOrCachePage(pageId)
+	if err != nil {
+		return nil, err
+	}
+	pageIds := []int{pageId}
+	for _, childPageId := range page.childPageIds {
+		childPageIds, err := pageHierarchy.subtreePageIds(childPageId, level-1)
+		if err != nil {
+			return nil, err
+		}
+		pageIds = append(pageIds, childPageIds...)
+	}
+	return pageIds, nil
+}
+
+func (pageHierarchy *PageHierarchy) height() (int, error) {
+	height := 1
+	for page := pageHierarchy.rootPage; !page.isLeaf(); height++ {
+		var err error
+		page, err = pageHierarchy.fetchOrCachePage(page.childPageIds[0])
+		if err != nil {
+			return 0, err
+		}
+	}
+	return height, nil
+}
+
+func (pageHierarchy PageHierarchy) isCachedNonLeaf(pageId int) bool {
 	page, found := pageHierarchy.pageById[pageId]
-	if found {
+	return found && !page.isLeaf()
+}
+
+func (pageHierarchy *PageHierarchy) release(pageIds []int) {
+	for _, pageId := range pageIds {
+		delete(pageHierarchy.pageById, pageId)
+	}
+	pageHierarchy.freePageList.release(pageIds)
+}
+
+// forEachKeyValuePair visits the key value pairs in key order, reading the pages that are not cached without caching
+// them.
+func (pageHierarchy *PageHierarchy) forEachKeyValuePair(page *Page, fn func(keyValuePair KeyValuePair) error) error {
+	if page.isLeaf() {
+		for _, keyValuePair := range page.keyValuePairs {
+			if err := fn(keyValuePair); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, childPageId := range page.childPageIds {
+		childPage, err := pageHierarchy.cachedOrReadPage(childPageId)
+		if err != nil {
+			return err
+		}
+		if err := pageHierarchy.forEachKeyValuePair(childPage, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchOrCachePage reads the page outside of pageByIdLock, the first of the concurrent readers of a page to cache
+// it wins.
+func (pageHierarchy *PageHierarchy) fetchOrCachePage(pageId int) (*Page, error) {
+	if page := pageHierarchy.PageById(pageId); page != nil {
 		return page, nil
 	}
 	page, err := pageHierarchy.pagePool.Read(pageId)
 	if err != nil {
 		return nil, err
 	}
+	pageHierarchy.pageByIdLock.Lock()
+	defer pageHierarchy.pageByIdLock.Unlock()
+	if cachedPage, found := pageHierarchy.pageById[pageId]; found {
+		return cachedPage, nil
+	}
 	pageHierarchy.pageById[pageId] = page
 	return page, nil
 }
 
+// cachedOrReadPage returns the cached page or reads the page without caching it, so that walking the whole tree
+// to inspect it does not fill the cache.
+func (pageHierarchy PageHierarchy) cachedOrReadPage(pageId int) (*Page, error) {
+	if page := pageHierarchy.PageById(pageId); page != nil {
+		return page, nil
+	}
+	return pageHierarchy.pagePool.Read(pageId)
+}
+
 func (pageHierarchy PageHierarchy) isPageEligibleForSplit(page *Page) bool {
 	return page.size() >= (pageHierarchy.allowedPageOccupancyPercentage * (pageHierarchy.pagePool.pageSize) / 100)
 }
 
+// isNonLeafEligibleForSplit tells whether the non-leaf page reached the split size, or could not take the separator
+// of a split below it. A separator is never longer than maxKeySize.
+func (pageHierarchy PageHierarchy) isNonLeafEligibleForSplit(page *Page) bool {
+	return !page.isLeaf() && (pageHierarchy.isPageEligibleForSplit(page) ||
+		page.size()+pageHierarchy.maxKeySize()+nonLeafCellOverhead > pageHierarchy.pagePool.pageSize)
+}
+
+// maxKeySize leaves room for at least four separators of the longest key in a non-leaf page.
+func (pageHierarchy PageHierarchy) maxKeySize() int {
+	return (pageHierarchy.pagePool.pageSize-pageHeaderSize)/4 - nonLeafCellOverhead
+}
+
+// isLeafEligibleForSplit tells whether the leaf page reached the split size with the pair it just took. A page with
+// a single pair is not split, the pair fits in a page on its own.
+func (pageHierarchy PageHierarchy) isLeafEligibleForSplit(page *Page) bool {
+	return page.isLeaf() && len(page.keyValuePairs) > 1 && pageHierarchy.isPageEligibleForSplit(page)
+}
+
+// leafSplitPolicy falls back to ByteBalancedSplit when a half of the leaf page split by the given policy would not
+// fit in a page, and fails when neither policy splits the page into two halves that fit.
+func (pageHierarchy PageHierarchy) leafSplitPolicy(page *Page, splitPolicy SplitPolicy) (SplitPolicy, error) {
+	for _, policy := range []SplitPolicy{splitPolicy, ByteBalancedSplit} {
+		splitIndex := policy.splitIndex(page.keyValuePairs)
+		leftPage := &Page{keyValuePairs: page.keyValuePairs[:splitIndex], compressKeyPrefix: page.compressKeyPrefix}
+		rightPage := &Page{keyValuePairs: page.keyValuePairs[splitIndex:], compressKeyPrefix: page.compressKeyPrefix}
+		if leftPage.size() <= pageHierarchy.pagePool.pageSize && rightPage.size() <= pageHierarchy.pagePool.pageSize {
+			return policy, nil
+		}
+	}
+	return splitPolicy, fmt.Errorf("page %v of %v bytes can not be split into two pages of the page size %v", page.id, page.size(), pageHierarchy.pagePool.pageSize)
+}
+
+// discardChanges drops the cached pages which may hold changes that were not written, reloads the root page from
+// the index file and returns the pages allocated by the failed change to the FreePageList.
+func (pageHierarchy *PageHierarchy) discardChanges(rootPageId int) error {
+	pageHierarchy.pageById = map[int]*Page{}
+	pageHierarchy.freePageList.release(pageHierarchy.allocatedPageIds)
+	pageHierarchy.allocatedPageIds = nil
+	return pageHierarchy.loadRootPage(rootPageId)
+}
+
+func (pageHierarchy PageHierarchy) isPageUnderflow(page *Page) bool {
+	return page.size() < (pageHierarchy.allowedPageOccupancyPercentage * (pageHierarchy.pagePool.pageSize) / 200)
+}
+
 func (pageHierarchy *PageHierarchy) allocateSinglePage() (*Page, error) {
 	pages, err := pageHierarchy.allocatePages(1)
 	if err != nil {
@@ -183,7 +736,9 @@ func (pageHierarchy *PageHierarchy) allocatePages(pageCount int) ([]*Page, error
 	pages := make([]*Page, pageCount)
 	for index := 0; index < pageCount; index++ {
 		newPage := NewPage(newPageId)
+		newPage.compressKeyPrefix = pageHierarchy.pagePool.keyPrefixCompression
 		pageHierarchy.pageById[newPageId] = newPage
+		pageHierarchy.allocatedPageIds = append(pageHierarchy.allocatedPageIds, newPageId)
 		pages[index] = newPage
 		newPageId = newPageId + 1
 	}
@@ -23,7 +23,7 @@ func TestReturnsPageById(t *testing.T) {
 		id: 0,
 	}
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	page := pageHierarchy.PageById(0)
 	if page.id != 0 {
@@ -38,7 +38,7 @@ func TestReturnsTheRootPageId(t *testing.T) {
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 	pageHierarchy.rootPage = &Page{id: 100}
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	rootPageId := pageHierarchy.RootPageId()
 	if rootPageId != 100 {
@@ -63,7 +63,7 @@ func TestReturnsTrueGivenPageIsEligibleForSplit(t *testing.T) {
 		},
 	}
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	isEligibleForSplit := pageHierarchy.isPageEligibleForSplit(page)
 	if isEligibleForSplit != true {
@@ -88,7 +88,7 @@ func TestReturnsFalseGivenPageIsNotEligibleForSplit(t *testing.T) {
 		},
 	}
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	isEligibleForSplit := pageHierarchy.isPageEligibleForSplit(page)
 	if isEligibleForSplit != false {
@@ -102,7 +102,7 @@ func TestDoesNotGetByKey(t *testing.T) {
 	pagePool := NewPagePool(indexFile, options)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{key: []byte("A")},
@@ -122,7 +122,7 @@ func TestGetsByKeyInRootLeafPage(t *testing.T) {
 	pagePool := NewPagePool(indexFile, options)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -186,13 +186,14 @@ func TestGetsByKeyInTheLeafPageWhichIsTheLeftChildOfRootPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	writeLeftPageToFile(options.FileName, options.PageSize)
 	writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 
 	expectedKeyValuePair := KeyValuePair{
 		key:   []byte("A"),
@@ -245,13 +246,14 @@ func TestGetsByKeyInTheLeafPageWhichIsTheRightChildOfRootPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	writeLeftPageToFile(options.FileName, options.PageSize)
 	writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 
 	expectedKeyValuePair := KeyValuePair{
 		key:   []byte("C"),
@@ -304,13 +306,14 @@ func TestGetsByKeyInTheLeafPageWhichIsTheRightChildOfRootPageGivenKeyIsFoundInTh
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	writeLeftPageToFile(options.FileName, options.PageSize)
 	writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 
 	expectedKeyValuePair := KeyValuePair{
 		key:   []byte("B"),
@@ -330,7 +333,7 @@ func TestPutsAKeyValuePairInRootLeafPage(t *testing.T) {
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -363,7 +366,7 @@ func TestUpdatesAKeyValuePairInRootLeafPage(t *testing.T) {
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -428,13 +431,14 @@ func TestPutsAKeyValuePairInTheRightPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	writeLeftPageToFile(options.FileName, options.PageSize)
 	writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 
 	_ = pageHierarchy.Put(KeyValuePair{key: []byte("D"), value: []byte("OS")})
 
@@ -453,7 +457,7 @@ func TestPutsAKeyValuePairAfterSplittingTheRootPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
 			key:   []byte("A"),
@@ -481,7 +485,7 @@ func TestPutsAKeyValuePairAfterSplittingTheRootPage(t *testing.T) {
 
 func TestSplitsTheRootPageAndCreatesANewRootWithKeyValuePairs(t *testing.T) {
 	options := Options{
-		PageSize:                 200,
+		PageSize:                 1100,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -490,7 +494,7 @@ func TestSplitsTheRootPageAndCreatesANewRootWithKeyValuePairs(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{
 		{
 			key:   []byte("A"),
@@ -509,7 +513,7 @@ func TestSplitsTheRootPageAndCreatesANewRootWithKeyValuePairs(t *testing.T) {
 	_ = pageHierarchy.Put(KeyValuePair{key: []byte("D"), value: []byte("File System")})
 
 	keyValuePairsOfNewRootPage := pageHierarchy.rootPage.AllKeyValuePairs()
-	expected := []KeyValuePair{{key: []byte("C")}}
+	expected := []KeyValuePair{{key: []byte("D")}}
 
 	if !reflect.DeepEqual(expected, keyValuePairsOfNewRootPage) {
 		t.Fatalf("Expected Key value pair in the new root to be %v, received %v", expected, keyValuePairsOfNewRootPage)
@@ -518,7 +522,7 @@ func TestSplitsTheRootPageAndCreatesANewRootWithKeyValuePairs(t *testing.T) {
 
 func TestSplitsTheRootPageAndWithKeyValuePairsInOldRoot(t *testing.T) {
 	options := Options{
-		PageSize:                 100,
+		PageSize:                 330,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -527,7 +531,7 @@ func TestSplitsTheRootPageAndWithKeyValuePairsInOldRoot(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 	existingRootPage := pageHierarchy.rootPage
 	existingRootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -547,7 +551,7 @@ func TestSplitsTheRootPageAndWithKeyValuePairsInOldRoot(t *testing.T) {
 	_ = pageHierarchy.Put(KeyValuePair{key: []byte("D"), value: []byte("File System")})
 
 	keyValuePairs := existingRootPage.AllKeyValuePairs()
-	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}
+	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}, {key: []byte("C"), value: []byte("Systems")}}
 
 	if !reflect.DeepEqual(expected, keyValuePairs) {
 		t.Fatalf("Expected Key value pair in the old root to be %v, received %v", expected, keyValuePairs)
@@ -556,7 +560,7 @@ func TestSplitsTheRootPageAndWithKeyValuePairsInOldRoot(t *testing.T) {
 
 func TestSplitsTheRootPageAndWithKeyValuePairsInRightSiblingPage(t *testing.T) {
 	options := Options{
-		PageSize:                 200,
+		PageSize:                 1100,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -565,7 +569,7 @@ func TestSplitsTheRootPageAndWithKeyValuePairsInRightSiblingPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageList(options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 	existingRootPage := pageHierarchy.rootPage
 	existingRootPage.keyValuePairs = []KeyValuePair{
 		{
@@ -585,7 +589,7 @@ func TestSplitsTheRootPageAndWithKeyValuePairsInRightSiblingPage(t *testing.T) {
 	_ = pageHierarchy.Put(KeyValuePair{key: []byte("D"), value: []byte("File System")})
 	rightSibling, _ := pagePool.Read(pageHierarchy.rootPage.childPageIds[1])
 	keyValuePairs := rightSibling.AllKeyValuePairs()
-	expected := []KeyValuePair{{key: []byte("C"), value: []byte("Systems")}, {key: []byte("D"), value: []byte("File System")}, {key: []byte("E"), value: []byte("OS")}}
+	expected := []KeyValuePair{{key: []byte("D"), value: []byte("File System")}, {key: []byte("E"), value: []byte("OS")}}
 
 	if !reflect.DeepEqual(expected, keyValuePairs) {
 		t.Fatalf("Expected Key value pair in the right sibling to be %v, received %v", expected, keyValuePairs)
@@ -629,7 +633,7 @@ func TestSplitsLeafPageAndAddsAKeyToTheRootPage(t *testing.T) {
 	}
 
 	options := Options{
-		PageSize:                 200,
+		PageSize:                 1100,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -638,19 +642,20 @@ func TestSplitsLeafPageAndAddsAKeyToTheRootPage(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageListWithStartingPgeId(4, options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	leftPage := writeLeftPageToFile(options.FileName, options.PageSize)
 	rightPage := writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 	pageHierarchy.pageById[2] = leftPage
 	pageHierarchy.pageById[3] = rightPage
 
 	_ = pageHierarchy.Put(KeyValuePair{key: []byte("E"), value: []byte("NFS")})
 
-	expected := []KeyValuePair{{key: []byte("B")}, {key: []byte("C")}}
+	expected := []KeyValuePair{{key: []byte("B")}, {key: []byte("E")}}
 	rootPageKeyValuePairs := pageHierarchy.rootPage.AllKeyValuePairs()
 
 	if !reflect.DeepEqual(expected, rootPageKeyValuePairs) {
@@ -695,7 +700,7 @@ func TestSplitsLeafPageAndPutsTheValueInTheRightSibling(t *testing.T) {
 	}
 
 	options := Options{
-		PageSize:                 200,
+		PageSize:                 1100,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -704,13 +709,14 @@ func TestSplitsLeafPageAndPutsTheValueInTheRightSibling(t *testing.T) {
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageListWithStartingPgeId(4, options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	leftPage := writeLeftPageToFile(options.FileName, options.PageSize)
 	rightPage := writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 	pageHierarchy.pageById[2] = leftPage
 	pageHierarchy.pageById[3] = rightPage
 
@@ -719,14 +725,6 @@ func TestSplitsLeafPageAndPutsTheValueInTheRightSibling(t *testing.T) {
 	resultantPage := getResult.page
 
 	expected := []KeyValuePair{
-		{
-			key:   []byte("C"),
-			value: []byte("Systems"),
-		},
-		{
-			key:   []byte("D"),
-			value: []byte("OS"),
-		},
 		{
 			key:   []byte("E"),
 			value: []byte("NFS"),
@@ -776,7 +774,7 @@ func TestSplitsLeafPageAndAddsTheNewPageAsTheRightmostChildOfTheRootPage(t *test
 	}
 
 	options := Options{
-		PageSize:                 200,
+		PageSize:                 1100,
 		FileName:                 "./test",
 		PreAllocatedPagePoolSize: 8,
 	}
@@ -785,13 +783,14 @@ func TestSplitsLeafPageAndAddsTheNewPageAsTheRightmostChildOfTheRootPage(t *test
 	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
 	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageListWithStartingPgeId(4, options.PreAllocatedPagePoolSize))
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
 
 	leftPage := writeLeftPageToFile(options.FileName, options.PageSize)
 	rightPage := writeRightPageToFile(options.FileName, options.PageSize)
 	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
 	pageHierarchy.pageById[2] = leftPage
 	pageHierarchy.pageById[3] = rightPage
 
@@ -800,14 +799,6 @@ func TestSplitsLeafPageAndAddsTheNewPageAsTheRightmostChildOfTheRootPage(t *test
 	resultantPage, _ := pagePool.Read(resultantPageId)
 
 	expected := []KeyValuePair{
-		{
-			key:   []byte("C"),
-			value: []byte("Systems"),
-		},
-		{
-			key:   []byte("D"),
-			value: []byte("OS"),
-		},
 		{
 			key:   []byte("E"),
 			value: []byte("NFS"),
@@ -820,6 +811,47 @@ func TestSplitsLeafPageAndAddsTheNewPageAsTheRightmostChildOfTheRootPage(t *test
 	}
 }
 
+func TestSplitsTheRightmostLeafPageInTheMiddleGivenTheKeyIsBeforeItsLastKey(t *testing.T) {
+	options := Options{
+		PageSize:                 1100,
+		FileName:                 "./test",
+		PreAllocatedPagePoolSize: 8,
+	}
+	indexFile, _ := OpenIndexFile(options)
+	pagePool := NewPagePool(indexFile, options)
+	_, _ = pagePool.Allocate(options.PreAllocatedPagePoolSize)
+	pageHierarchy := NewPageHierarchy(pagePool, 10, DefaultFreePageListWithStartingPgeId(4, options.PreAllocatedPagePoolSize))
+
+	defer deleteFile(pagePool.storage)
+
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}}
+	rightPage := &Page{
+		id: 3,
+		keyValuePairs: []KeyValuePair{
+			{key: []byte("B"), value: []byte("Storage")},
+			{key: []byte("C"), value: []byte("Systems")},
+			{key: []byte("D"), value: []byte("OS")},
+		},
+	}
+	writeToAATestFileAtOffset(options.FileName, leftPage.MarshalBinary(), int64(options.PageSize*leftPage.id))
+	writeToAATestFileAtOffset(options.FileName, rightPage.MarshalBinary(), int64(options.PageSize*rightPage.id))
+
+	pageHierarchy.rootPage.keyValuePairs = []KeyValuePair{{key: []byte("B")}}
+	pageHierarchy.rootPage.childPageIds = []int{2, 3}
+	pageHierarchy.rootPage.level = 1
+	pageHierarchy.pageById[2] = leftPage
+	pageHierarchy.pageById[3] = rightPage
+
+	_ = pageHierarchy.Put(KeyValuePair{key: []byte("BB"), value: []byte("NFS")})
+
+	expected := []KeyValuePair{{key: []byte("B")}, {key: []byte("C")}}
+	rootPageKeyValuePairs := pageHierarchy.rootPage.AllKeyValuePairs()
+
+	if !reflect.DeepEqual(expected, rootPageKeyValuePairs) {
+		t.Fatalf("Expected Key value pair in the root page to be %v, received %v", expected, rootPageKeyValuePairs)
+	}
+}
+
 func TestAllocatesPagesFromPagePoolGivenFreePageListIsEmpty(t *testing.T) {
 	options := Options{
 		FileName:                 "./test",
@@ -832,7 +864,7 @@ func TestAllocatesPagesFromPagePoolGivenFreePageListIsEmpty(t *testing.T) {
 	emptyFreePageList := &FreePageList{}
 	pageHierarchy := NewPageHierarchy(pagePool, 10, emptyFreePageList)
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pages, _ := pageHierarchy.allocatePages(2)
 	expectedPageIds := []int{5, 6}
@@ -870,7 +902,7 @@ func TestWritesDirtyPagesToStorage(t *testing.T) {
 
 	pageHierarchy.pageById[0] = pageA()
 
-	defer deleteFile(pagePool.indexFile)
+	defer deleteFile(pagePool.storage)
 
 	pageHierarchy.Write([]DirtyPage{{page: pageA()}})
 
@@ -1,14 +1,36 @@
 package index
 
+import (
+	"b+tree/index/schema"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var ErrPageIdOverflow = errors.New("page id overflows the addressable range")
+
 type PagePool struct {
-	indexFile *IndexFile
-	pageSize  int
-	pageCount int
+	storage        Storage
+	pageSize       int
+	pageCount      int
+	maxGrowthPages int
+	lsn            uint64
+
+	keyPrefixCompression bool
+	compression          Compression
+	keyProvider          KeyProvider
+	aeadByKeyId          *sync.Map
 }
 
-func NewPagePool(indexFile *IndexFile, options Options) *PagePool {
+func NewPagePool(storage Storage, options Options) *PagePool {
 	pagePool := &PagePool{
-		indexFile: indexFile,
+		storage:              storage,
+		maxGrowthPages:       options.MaxGrowthPages,
+		keyPrefixCompression: options.KeyPrefixCompression,
+		compression:          options.Compression,
+		keyProvider:          options.KeyProvider,
+		aeadByKeyId:          &sync.Map{},
 	}
 	pagePool.pageSize = options.PageSize
 	pagePool.pageCount = pagePool.numberOfPages()
@@ -18,42 +40,211 @@ func NewPagePool(indexFile *IndexFile, options Options) *PagePool {
 
 func (pagePool *PagePool) Allocate(pages int) (int, error) {
 	nextPageId := pagePool.pageCount
-	targetSize := pagePool.indexFile.size + int64(pages*pagePool.pageSize)
-	if err := pagePool.indexFile.ResizeTo(targetSize); err != nil {
-		return 0, err
+	if int64(pages) > math.MaxInt64/int64(pagePool.pageSize)-int64(pagePool.pageCount) {
+		return 0, fmt.Errorf("allocating %v pages after %v pages: %w", pages, pagePool.pageCount, ErrPageIdOverflow)
 	}
-	pagePool.pageCount = pagePool.numberOfPages()
+	if pagePool.offsetOf(pagePool.pageCount+pages) > pagePool.storage.Size() {
+		if err := pagePool.storage.Grow(pagePool.offsetOf(pagePool.pageCount + pagePool.growthPages(pages))); err != nil {
+			return 0, err
+		}
+	}
+	pagePool.pageCount = pagePool.pageCount + pages
 	return nextPageId, nil
 }
 
+// Truncate drops all the pages from pageCount onwards.
+func (pagePool *PagePool) Truncate(pageCount int) error {
+	if err := pagePool.storage.Truncate(pagePool.offsetOf(pageCount)); err != nil {
+		return err
+	}
+	pagePool.pageCount = pageCount
+	return nil
+}
+
 func (pagePool PagePool) Read(pageId int) (*Page, error) {
-	//Assignment:B+TreeGet:4:Read the entire page from pagePool.indexFile
-	var bytes []byte
-	var err error = nil
+	bytes, err := pagePool.storage.ReadPage(pagePool.offsetOf(pageId), pagePool.pageSize)
 	if err != nil {
 		return nil, err
 	}
-	page := &Page{id: pageId}
-	page.UnMarshalBinary(bytes)
+	bytes, decrypted, err := pagePool.decryptIfEncrypted(pageId, bytes)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{id: pageId, compressKeyPrefix: pagePool.keyPrefixCompression}
+	if err := page.UnMarshalBinary(bytes); err != nil {
+		return nil, err
+	}
+	if header, _ := UnMarshalPageHeader(bytes); Compression(header.Codec) == NoCompression && !decrypted {
+		page.image = bytes
+	}
 	return page, nil
 }
 
-func (pagePool *PagePool) Write(page *Page) {
-	pagePool.indexFile.writeAt(pagePool.offsetOf(page.id), page.MarshalBinary())
+// View returns a view over the page that refers to the storage directly when the storage is a PageViewer,
+// otherwise the view is over a copy of the page.
+func (pagePool PagePool) View(pageId int) (pageView, error) {
+	var buffer []byte
+	var err error
+	if pageViewer, ok := pagePool.storage.(PageViewer); ok {
+		buffer, err = pageViewer.ViewPage(pagePool.offsetOf(pageId), pagePool.pageSize)
+	} else {
+		buffer, err = pagePool.storage.ReadPage(pagePool.offsetOf(pageId), pagePool.pageSize)
+	}
+	if err != nil {
+		return pageView{}, err
+	}
+	if buffer, _, err = pagePool.decryptIfEncrypted(pageId, buffer); err != nil {
+		return pageView{}, err
+	}
+	return newPageView(pageId, buffer)
+}
+
+// Write stamps the page with the next log sequence number before writing it.
+// Only the bytes that changed since the page was last read or written go to the storage.
+func (pagePool *PagePool) Write(page *Page) error {
+	pagePool.lsn = pagePool.lsn + 1
+	page.lsn = pagePool.lsn
+	if page.compressKeyPrefix != pagePool.keyPrefixCompression {
+		page.compressKeyPrefix = pagePool.keyPrefixCompression
+		page.resetLayout()
+	}
+	if pagePool.keyProvider != nil {
+		return pagePool.writeEncrypted(page)
+	}
+	if pagePool.compression != NoCompression && page.isLeaf() {
+		written, err := pagePool.writeCompressed(page)
+		if written || err != nil {
+			return err
+		}
+	}
+
+	changedRanges, err := page.changedRanges(pagePool.pageSize)
+	if err != nil {
+		return err
+	}
+	offset := pagePool.offsetOf(page.id)
+	for _, changedRange := range changedRanges {
+		if err := pagePool.storage.WritePage(offset+int64(changedRange.from), page.image[changedRange.from:changedRange.to]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCompressed writes the leaf page as a whole with its body compressed, and reports false when the compressed
+// page would not be smaller so that the page is written uncompressed.
+func (pagePool *PagePool) writeCompressed(page *Page) (bool, error) {
+	if page.size() > pagePool.pageSize {
+		return false, fmt.Errorf("page %v of %v bytes does not fit in the page size %v", page.id, page.size(), pagePool.pageSize)
+	}
+	compressed, err := compressPage(page.MarshalBinary(), pagePool.compression)
+	if compressed == nil || err != nil {
+		return false, err
+	}
+	page.dropImage()
+	return true, pagePool.storage.WritePage(pagePool.offsetOf(page.id), compressed)
+}
+
+// writeEncrypted writes the page as a whole, compressed first if the compression applies, since every write of an
+// encrypted page needs a fresh nonce over the entire page.
+func (pagePool *PagePool) writeEncrypted(page *Page) error {
+	if page.size() > pagePool.pageSize {
+		return fmt.Errorf("page %v of %v bytes does not fit in the page size %v", page.id, page.size(), pagePool.pageSize)
+	}
+	buffer := page.MarshalBinary()
+	if pagePool.compression != NoCompression && page.isLeaf() {
+		compressed, err := compressPage(buffer, pagePool.compression)
+		if err != nil {
+			return err
+		}
+		if compressed != nil {
+			buffer = compressed
+		}
+	}
+	encrypted, err := pagePool.encryptPage(page.id, buffer)
+	if err != nil {
+		return err
+	}
+	page.dropImage()
+	return pagePool.storage.WritePage(pagePool.offsetOf(page.id), encrypted)
+}
+
+func (pagePool PagePool) decryptIfEncrypted(pageId int, buffer []byte) ([]byte, bool, error) {
+	header, err := UnMarshalPageHeader(buffer)
+	if err != nil || header.Encryption == 0 {
+		return buffer, false, nil
+	}
+	decrypted, err := pagePool.decryptPage(pageId, buffer)
+	return decrypted, true, err
+}
+
+// ReadMetaPage also resumes the log sequence numbers and the count of allocated pages from the ones recorded in the
+// meta page, the pages reserved by the last growth of the file past that count are reused by the next allocations.
+func (pagePool *PagePool) ReadMetaPage() (*MetaPage, error) {
+	bytes, err := pagePool.storage.ReadPage(pagePool.offsetOf(metaPageId), int((&schema.PersistentMetaPage{}).Size()))
+	if err != nil {
+		return nil, err
+	}
+	metaPage := &MetaPage{}
+	if err := metaPage.UnMarshalBinary(bytes); err != nil {
+		return nil, err
+	}
+	pagePool.lsn = metaPage.lsn
+	if metaPage.pageCount > 0 && metaPage.pageCount <= pagePool.pageCount {
+		pagePool.pageCount = metaPage.pageCount
+	}
+	return metaPage, nil
+}
+
+func (pagePool *PagePool) WriteMetaPage(metaPage *MetaPage) error {
+	metaPage.lsn = pagePool.lsn
+	metaPage.pageCount = pagePool.pageCount
+	if pagePool.keyProvider != nil {
+		keyId, _, err := pagePool.keyProvider.CurrentKey()
+		if err != nil {
+			return err
+		}
+		metaPage.keyId = keyId
+	}
+	return pagePool.storage.WritePage(pagePool.offsetOf(metaPageId), metaPage.MarshalBinary())
 }
 
 func (pagePool PagePool) offsetOf(pageId int) int64 {
-	return int64(pagePool.pageSize * pageId)
+	return int64(pagePool.pageSize) * int64(pageId)
+}
+
+// pageIdOf converts a page id read from a page into an int, failing for the ids an int can not hold instead of
+// wrapping them around.
+func pageIdOf(persistentPageId uint64) (int, error) {
+	if persistentPageId > math.MaxInt {
+		return 0, fmt.Errorf("page id %v: %w", persistentPageId, ErrPageIdOverflow)
+	}
+	return int(persistentPageId), nil
 }
 
 func (pagePool PagePool) ContainsZeroPages() bool {
 	return pagePool.pageCount == 0
 }
 
+func (pagePool *PagePool) Sync() error {
+	return pagePool.storage.Sync()
+}
+
 func (pagePool *PagePool) Close() error {
-	return pagePool.indexFile.Close()
+	return pagePool.storage.Close()
+}
+
+func (pagePool PagePool) growthPages(pages int) int {
+	growthPages := pagePool.pageCount
+	if growthPages > pagePool.maxGrowthPages {
+		growthPages = pagePool.maxGrowthPages
+	}
+	if growthPages < pages {
+		growthPages = pages
+	}
+	return growthPages
 }
 
 func (pagePool PagePool) numberOfPages() int {
-	return int(pagePool.indexFile.size) / pagePool.pageSize
+	return int(pagePool.storage.Size()) / pagePool.pageSize
 }
@@ -1,8 +1,11 @@
 package index
 
 import (
+	"errors"
+	"math"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -111,7 +114,7 @@ func TestAllocationOf5PagesShouldIncreaseTheFileSize(t *testing.T) {
 
 	_, _ = pagePool.Allocate(5)
 	expectedFileSize := int64(5 * os.Getpagesize())
-	actualFileSize := pagePool.indexFile.size
+	actualFileSize := pagePool.storage.Size()
 
 	if actualFileSize != expectedFileSize {
 		t.Fatalf("Expected file size to be %v, received %v", expectedFileSize, actualFileSize)
@@ -218,6 +221,7 @@ func TestReadsANonLeafPageWithChildPageIds(t *testing.T) {
 		keyValuePairs: []KeyValuePair{
 			{key: []byte("A")},
 		},
+		level:        1,
 		childPageIds: []int{10, 20},
 	}
 
@@ -248,6 +252,7 @@ func TestReadsANonLeafPageWithKeyValuePairs(t *testing.T) {
 		keyValuePairs: []KeyValuePair{
 			{key: []byte("A")},
 		},
+		level:        1,
 		childPageIds: []int{10, 20},
 	}
 
@@ -283,3 +288,145 @@ func writeToAATestFileAtOffset(fileName string, content []byte, offset int64) {
 	_, _ = file.Write(content)
 	_ = file.Close()
 }
+
+func TestGrowsTheIndexFileGeometricallyWhileAllocatingPages(t *testing.T) {
+	options := DefaultOptions()
+	options.MaxGrowthPages = 8
+	indexFile, _ := OpenIndexFile(options)
+	pagePool := NewPagePool(indexFile, options)
+
+	defer deleteFile(indexFile)
+
+	_, _ = pagePool.Allocate(4)
+	_, _ = pagePool.Allocate(1)
+
+	expectedFileSize := int64(8 * options.PageSize)
+	actualFileSize := pagePool.storage.Size()
+
+	if actualFileSize != expectedFileSize {
+		t.Fatalf("Expected file size to be %v, received %v", expectedFileSize, actualFileSize)
+	}
+	if pagePool.pageCount != 5 {
+		t.Fatalf("Expected page count to be %v, received %v", 5, pagePool.pageCount)
+	}
+}
+
+func TestDoesNotGrowTheIndexFileGivenItHasCapacityForTheAllocatedPages(t *testing.T) {
+	options := DefaultOptions()
+	options.MaxGrowthPages = 8
+	indexFile, _ := OpenIndexFile(options)
+	pagePool := NewPagePool(indexFile, options)
+
+	defer deleteFile(indexFile)
+
+	_, _ = pagePool.Allocate(4)
+	_, _ = pagePool.Allocate(1)
+	pageId, _ := pagePool.Allocate(3)
+
+	expectedFileSize := int64(8 * options.PageSize)
+	actualFileSize := pagePool.storage.Size()
+
+	if actualFileSize != expectedFileSize {
+		t.Fatalf("Expected file size to be %v, received %v", expectedFileSize, actualFileSize)
+	}
+	if pageId != 5 {
+		t.Fatalf("Expected page id to be %v, received %v", 5, pageId)
+	}
+}
+
+func TestCapsTheGrowthOfTheIndexFileByMaxGrowthPages(t *testing.T) {
+	options := DefaultOptions()
+	options.MaxGrowthPages = 2
+	indexFile, _ := OpenIndexFile(options)
+	pagePool := NewPagePool(indexFile, options)
+
+	defer deleteFile(indexFile)
+
+	_, _ = pagePool.Allocate(8)
+	_, _ = pagePool.Allocate(1)
+
+	expectedFileSize := int64(10 * options.PageSize)
+	actualFileSize := pagePool.storage.Size()
+
+	if actualFileSize != expectedFileSize {
+		t.Fatalf("Expected file size to be %v, received %v", expectedFileSize, actualFileSize)
+	}
+}
+
+func TestTruncatesPagesFromTheEndOfThePool(t *testing.T) {
+	options := DefaultOptions()
+	indexFile, _ := OpenIndexFile(options)
+	pagePool := NewPagePool(indexFile, options)
+
+	defer deleteFile(indexFile)
+
+	_, _ = pagePool.Allocate(5)
+	_ = pagePool.Truncate(2)
+
+	expectedFileSize := int64(2 * options.PageSize)
+	actualFileSize := pagePool.storage.Size()
+
+	if actualFileSize != expectedFileSize || pagePool.pageCount != 2 {
+		t.Fatalf("Expected file size to be %v and page count 2, received %v and %v", expectedFileSize, actualFileSize, pagePool.pageCount)
+	}
+}
+
+func TestStampsEachWrittenPageWithTheNextLsn(t *testing.T) {
+	options := DefaultOptions()
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(2)
+
+	_ = pagePool.Write(&Page{id: 0})
+	_ = pagePool.Write(&Page{id: 1})
+
+	readPage, _ := pagePool.Read(1)
+	if readPage.lsn != 2 {
+		t.Fatalf("Expected lsn of the page to be 2, received %v", readPage.lsn)
+	}
+}
+
+func TestWritesACompressedLeafPageAndReadsItBack(t *testing.T) {
+	options := Options{PageSize: os.Getpagesize(), InMemory: true, Compression: LZCompression}
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(2)
+
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+		{key: []byte("B"), value: []byte(strings.Repeat(`{"status":"active"}`, 10))},
+	}
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: keyValuePairs})
+
+	buffer, _ := pagePool.storage.ReadPage(pagePool.offsetOf(1), pagePool.pageSize)
+	header, _ := UnMarshalPageHeader(buffer)
+	if Compression(header.Codec) != LZCompression {
+		t.Fatalf("Expected the page to be written with LZCompression, received codec %v", header.Codec)
+	}
+	page, _ := pagePool.Read(1)
+	if !reflect.DeepEqual(keyValuePairs, page.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", keyValuePairs, page.AllKeyValuePairs())
+	}
+}
+
+func TestViewsANonLeafPageWithChildPageIdsBeyondTheUint32Range(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A")}}, level: 1, childPageIds: []int{1 << 33, 1<<40 + 7}})
+
+	view, _ := pagePool.View(1)
+	for index, expected := range []int{1 << 33, 1<<40 + 7} {
+		if childPageId, err := view.childPageIdAt(index); err != nil || childPageId != expected {
+			t.Fatalf("Expected child page id to be %v, received %v with error %v", expected, childPageId, err)
+		}
+	}
+}
+
+func TestFailsToAllocatePagesBeyondTheAddressableRange(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+
+	_, err := pagePool.Allocate(math.MaxInt64 / pagePool.pageSize)
+	if !errors.Is(err, ErrPageIdOverflow) {
+		t.Fatalf("Expected ErrPageIdOverflow, received %v", err)
+	}
+	if pagePool.pageCount != 2 {
+		t.Fatalf("Expected page count to remain 2, received %v", pagePool.pageCount)
+	}
+}
@@ -0,0 +1,149 @@
+package index
+
+import (
+	"b+tree/index/schema"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// pageView reads a page in place. Keys and values are sliced out of the page buffer through the slot directory,
+// nothing is decoded upfront and nothing is copied, except for the keys of a page with a key prefix which are
+// assembled from the prefix and the rest of the key, and for compressed pages which are decompressed first.
+// The checksum is not verified, cells are only bounds checked.
+type pageView struct {
+	id     int
+	buffer []byte
+	header *schema.PersistentPageHeader
+}
+
+func newPageView(pageId int, buffer []byte) (pageView, error) {
+	header, err := UnMarshalPageHeader(buffer)
+	if err != nil {
+		return pageView{}, err
+	}
+	if header.Encryption != 0 {
+		return pageView{}, fmt.Errorf("page %v: %w", pageId, ErrPageEncrypted)
+	}
+	if Compression(header.Codec) != NoCompression {
+		if buffer, err = decompressPage(buffer, header); err != nil {
+			return pageView{}, fmt.Errorf("page %v could not be decompressed: %w", pageId, err)
+		}
+	}
+	if header.FreeSpaceOffset != 0 {
+		if err := validateLayout(buffer, header); err != nil {
+			return pageView{}, fmt.Errorf("page %v has an invalid layout: %w", pageId, err)
+		}
+	}
+	return pageView{id: pageId, buffer: buffer, header: header}, nil
+}
+
+func (view pageView) isLeaf() bool {
+	return view.header.PageType == LeafPage
+}
+
+func (view pageView) entryCount() int {
+	return int(view.header.EntryCount)
+}
+
+func (view pageView) keyPrefix() []byte {
+	return view.buffer[view.header.PrefixOffset : view.header.PrefixOffset+view.header.PrefixLength]
+}
+
+// keyBoundsAt returns the start and the end offset of the key (without the key prefix) in the cell at the index,
+// the rest of the cell (value or child page id) follows the key.
+func (view pageView) keyBoundsAt(index int) (int, int, error) {
+	offset := slotAt(view.buffer, index)
+	length, err := cellLength(view.buffer, offset, view.header.PageType)
+	if err != nil {
+		return 0, 0, fmt.Errorf("page %v: %w", view.id, err)
+	}
+	keyLength, keyLengthSize := binary.Uvarint(view.buffer[offset:])
+	from := offset + keyLengthSize
+	if from+int(keyLength) > offset+length {
+		return 0, 0, fmt.Errorf("page %v: cell at offset %v overflows", view.id, offset)
+	}
+	return from, from + int(keyLength), nil
+}
+
+func (view pageView) keyAt(index int) ([]byte, error) {
+	from, to, err := view.keyBoundsAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return view.keyOf(from, to), nil
+}
+
+func (view pageView) keyOf(from, to int) []byte {
+	prefix := view.keyPrefix()
+	if len(prefix) == 0 {
+		return view.buffer[from:to:to]
+	}
+	return append(append(make([]byte, 0, len(prefix)+to-from), prefix...), view.buffer[from:to]...)
+}
+
+// compareKeyAt compares the key at the index with the given key without assembling the key at the index.
+func (view pageView) compareKeyAt(index int, key []byte) (int, error) {
+	from, to, err := view.keyBoundsAt(index)
+	if err != nil {
+		return 0, err
+	}
+	prefix := view.keyPrefix()
+	if len(key) < len(prefix) {
+		if comparison := bytes.Compare(prefix[:len(key)], key); comparison != 0 {
+			return comparison, nil
+		}
+		return 1, nil
+	}
+	if comparison := bytes.Compare(prefix, key[:len(prefix)]); comparison != 0 {
+		return comparison, nil
+	}
+	return bytes.Compare(view.buffer[from:to], key[len(prefix):]), nil
+}
+
+func (view pageView) keyValuePairAt(index int) (KeyValuePair, error) {
+	from, to, err := view.keyBoundsAt(index)
+	if err != nil {
+		return KeyValuePair{}, err
+	}
+	valueLength, valueLengthSize := binary.Uvarint(view.buffer[to:])
+	valueFrom := to + valueLengthSize
+	valueTo := valueFrom + int(valueLength)
+	return KeyValuePair{key: view.keyOf(from, to), value: view.buffer[valueFrom:valueTo:valueTo]}, nil
+}
+
+// childPageIdAt returns the id of the child page at the index, the first child page id is kept in the header and
+// the rest alongside the keys.
+func (view pageView) childPageIdAt(index int) (int, error) {
+	if index == 0 {
+		return pageIdOf(view.header.FirstChildPageId)
+	}
+	_, to, err := view.keyBoundsAt(index - 1)
+	if err != nil {
+		return 0, err
+	}
+	childPageId, childPageIdSize := binary.Uvarint(view.buffer[to:])
+	if childPageIdSize <= 0 {
+		return 0, fmt.Errorf("page %v has an invalid child page id at %v", view.id, index)
+	}
+	return pageIdOf(childPageId)
+}
+
+// search returns the index of the first key greater than or equal to the given key when equal is true,
+// and the index of the first key greater than the given key otherwise.
+func (view pageView) search(key []byte, equal bool) (int, error) {
+	var err error
+	index := sort.Search(view.entryCount(), func(index int) bool {
+		if err != nil {
+			return true
+		}
+		var comparison int
+		comparison, err = view.compareKeyAt(index, key)
+		if equal {
+			return comparison >= 0
+		}
+		return comparison > 0
+	})
+	return index, err
+}
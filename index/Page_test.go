@@ -1,6 +1,8 @@
 package index
 
 import (
+	"errors"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -116,6 +118,7 @@ func TestUnMarshalsANonLeafPageWithKey(t *testing.T) {
 				key: []byte("C"),
 			},
 		},
+		level:        1,
 		childPageIds: []int{10, 0},
 	}
 	bytes := page.MarshalBinary()
@@ -136,6 +139,7 @@ func TestUnMarshalsANonLeafPageWithChildPageId(t *testing.T) {
 				key: []byte("C"),
 			},
 		},
+		level:        1,
 		childPageIds: []int{10, 0},
 	}
 	bytes := page.MarshalBinary()
@@ -155,6 +159,7 @@ func TestUnMarshalsANonLeafPageWithMultipleChildPageIds(t *testing.T) {
 			{key: []byte("C")},
 			{key: []byte("D")},
 		},
+		level:        1,
 		childPageIds: []int{10, 15, 20},
 	}
 	bytes := page.MarshalBinary()
@@ -170,6 +175,59 @@ func TestUnMarshalsANonLeafPageWithMultipleChildPageIds(t *testing.T) {
 	}
 }
 
+func TestUnMarshalsANonLeafPageWithChildPageIdsBeyondTheUint32Range(t *testing.T) {
+	page := Page{
+		keyValuePairs: []KeyValuePair{
+			{key: []byte("C")},
+			{key: []byte("D")},
+		},
+		level:        1,
+		childPageIds: []int{1 << 33, 1<<40 + 7, 5},
+	}
+	bytes := page.MarshalBinary()
+
+	newPage := &Page{}
+	newPage.UnMarshalBinary(bytes)
+
+	expected := []int{1 << 33, 1<<40 + 7, 5}
+	if !reflect.DeepEqual(newPage.childPageIds, expected) {
+		t.Fatalf("Expected child page ids to be %v, received %v", expected, newPage.childPageIds)
+	}
+}
+
+func TestUnMarshalsANonLeafPageWithoutChildPageIdsAsANonLeafPage(t *testing.T) {
+	page := Page{level: 1}
+	bytes := page.MarshalBinary()
+
+	newPage := &Page{}
+	_ = newPage.UnMarshalBinary(bytes)
+
+	if newPage.isLeaf() || newPage.level != 1 || len(newPage.childPageIds) != 0 {
+		t.Fatalf("Expected a non-leaf page at level 1 without child page ids, received level %v and child page ids %v", newPage.level, newPage.childPageIds)
+	}
+	header, _ := UnMarshalPageHeader(bytes)
+	if header.PageType != NonLeafPage {
+		t.Fatalf("Expected the page type to be NonLeafPage, received %v", header.PageType)
+	}
+}
+
+func TestFailsToUnMarshalANonLeafPageWithAChildPageIdBeyondTheIntRange(t *testing.T) {
+	page := Page{
+		keyValuePairs: []KeyValuePair{{key: []byte("C")}},
+		level:         1,
+		childPageIds:  []int{10, 20},
+	}
+	bytes := page.MarshalBinary()
+	header, _ := UnMarshalPageHeader(bytes)
+	header.FirstChildPageId = math.MaxUint64
+	_, _ = header.Marshal(bytes)
+
+	err := (&Page{}).UnMarshalBinary(bytes)
+	if !errors.Is(err, ErrPageIdOverflow) {
+		t.Fatalf("Expected ErrPageIdOverflow, received %v", err)
+	}
+}
+
 func TestUnMarshalsAPageWithMultipleKeyValuePairs(t *testing.T) {
 	page := Page{
 		keyValuePairs: []KeyValuePair{
@@ -235,6 +293,7 @@ func TestInsertsAtAnIndexInAPageWhichIsNonLeaf(t *testing.T) {
 		keyValuePairs: []KeyValuePair{
 			{key: []byte("A")},
 		},
+		level:        1,
 		childPageIds: []int{1},
 	}
 	page.insertAt(1, KeyValuePair{key: []byte("D"), value: []byte("Operating")})
@@ -251,6 +310,7 @@ func TestInsertsAtAnIndexInAPageWhichIsNonLeaf(t *testing.T) {
 
 func TestInsertsChildPageAtAnIndex(t *testing.T) {
 	page := &Page{
+		level:        1,
 		childPageIds: []int{8, 10, 14},
 	}
 	childPage := NewPage(11)
@@ -270,9 +330,10 @@ func TestSplitsALeafPageWithKeyValuePairs(t *testing.T) {
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{0}
+	parentPage.level = 1
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 0)
+	_, _ = page.split(parentPage, siblingPage, 0, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := page.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}
@@ -289,9 +350,10 @@ func TestSplitsALeafPageWithKeyValuePairsInParent(t *testing.T) {
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{0}
+	parentPage.level = 1
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 0)
+	_, _ = page.split(parentPage, siblingPage, 0, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := parentPage.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("B")}}
@@ -308,9 +370,10 @@ func TestSplitsALeafPageWithKeyValuePairsInSibling(t *testing.T) {
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{0}
+	parentPage.level = 1
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 0)
+	_, _ = page.split(parentPage, siblingPage, 0, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := siblingPage.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("B"), value: []byte("Systems")}}
@@ -324,15 +387,17 @@ func TestSplitsANonLeafPageWithKeyValuePairsWithEvenNumberOfKeyValuePairs(t *tes
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13, 14},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := page.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("Q")}}
@@ -346,15 +411,17 @@ func TestSplitsANonLeafPageWithKeyValuePairsWithOddNumberOfKeyValuePairs(t *test
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := page.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("O")}}
@@ -368,15 +435,17 @@ func TestSplitsANonLeafPageWithKeyValuePairsInSiblingWithEvenNumberOfKeyValuePai
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13, 14},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := siblingPage.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}}
@@ -390,15 +459,17 @@ func TestSplitsANonLeafPageWithKeyValuePairsInSiblingWithOddNumberOfKeyValuePair
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := siblingPage.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("J")}}
@@ -412,15 +483,17 @@ func TestSplitsANonLeafPageWithChildPageIdsWithEvenNumberOfChildPageIds(t *testi
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	childPageIdsAfterSplit := page.childPageIds
 	expected := []int{12, 13}
@@ -434,15 +507,17 @@ func TestSplitsANonLeafPageWithChildPageIdsInSiblingPageWithEvenNumberOfChildPag
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	childPageIdsAfterSplit := siblingPage.childPageIds
 	expected := []int{10, 11}
@@ -456,15 +531,17 @@ func TestSplitsANonLeafPageWithChildPageIdsWithOddNumberOfChildPageIds(t *testin
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13, 14},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	childPageIdsAfterSplit := page.childPageIds
 	expected := []int{13, 14}
@@ -478,15 +555,17 @@ func TestSplitsANonLeafPageWithChildPageIdsInSiblingPageWithOddNumberOfChildPage
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13, 14},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	childPageIdsAfterSplit := siblingPage.childPageIds
 	expected := []int{10, 11, 12}
@@ -500,15 +579,17 @@ func TestSplitsANonLeafPageWithAKeyValuePairAddedToParent(t *testing.T) {
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13, 14},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5, 6}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	parentPageKeyValuePairs := parentPage.keyValuePairs
 	expected := []KeyValuePair{{key: []byte("S")}, {key: []byte("O")}}
@@ -522,15 +603,17 @@ func TestSplitsANonLeafPageWithKeyValuePairsInParent(t *testing.T) {
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	keyValuePairsAfterSplit := parentPage.AllKeyValuePairs()
 	expected := []KeyValuePair{{key: []byte("S")}, {key: []byte("O")}}
@@ -544,15 +627,17 @@ func TestSplitsANonLeafPageWithChildPageIdAdddedToParent(t *testing.T) {
 	page := &Page{
 		id:            5,
 		keyValuePairs: []KeyValuePair{{key: []byte("J")}, {key: []byte("L")}, {key: []byte("O")}, {key: []byte("Q")}},
+		level:         1,
 		childPageIds:  []int{10, 11, 12, 13},
 	}
 	parentPage := NewPage(100)
 	parentPage.childPageIds = []int{5}
+	parentPage.level = 1
 	parentPage.keyValuePairs = []KeyValuePair{{key: []byte("S")}}
 
 	siblingPage := NewPage(200)
 
-	_, _ = page.split(parentPage, siblingPage, 1)
+	_, _ = page.split(parentPage, siblingPage, 1, CountBalancedSplit)
 
 	childPageIdsOfParent := parentPage.childPageIds
 	expected := []int{5, 200}
@@ -568,7 +653,7 @@ func TestReturnsTheSizeOfALeafPage(t *testing.T) {
 		keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}},
 	}
 	size := page.size()
-	expected := 13
+	expected := 100
 
 	if expected != size {
 		t.Fatalf("Expected leaf page size to be %v, received %v", expected, size)
@@ -579,12 +664,196 @@ func TestReturnsTheSizeOfANonLeafPage(t *testing.T) {
 	page := &Page{
 		id:            0,
 		keyValuePairs: []KeyValuePair{{key: []byte("A")}},
+		level:         1,
 		childPageIds:  []int{10, 11},
 	}
 	size := page.size()
-	expected := 14
+	expected := 92
 
 	if expected != size {
 		t.Fatalf("Expected non-leaf page size to be %v, received %v", expected, size)
 	}
 }
+
+func TestDeletesARangeOfKeyValuePairs(t *testing.T) {
+	page := &Page{
+		id: 0,
+		keyValuePairs: []KeyValuePair{
+			{key: []byte("A")}, {key: []byte("B")}, {key: []byte("C")}, {key: []byte("D")},
+		},
+	}
+	page.deleteRange(1, 3)
+
+	expected := []KeyValuePair{{key: []byte("A")}, {key: []byte("D")}}
+	if !reflect.DeepEqual(expected, page.keyValuePairs) {
+		t.Fatalf("Expected key value pairs after deleting range to be %v, received %v", expected, page.keyValuePairs)
+	}
+}
+
+func TestDeletesTheLeftmostChildrenAlongWithTheirSeparators(t *testing.T) {
+	page := &Page{
+		id:            0,
+		keyValuePairs: []KeyValuePair{{key: []byte("B")}, {key: []byte("C")}, {key: []byte("D")}},
+		level:         1,
+		childPageIds:  []int{10, 11, 12, 13},
+	}
+	page.deleteChildrenRange(0, 2)
+
+	expectedKeyValuePairs := []KeyValuePair{{key: []byte("D")}}
+	expectedChildPageIds := []int{12, 13}
+
+	if !reflect.DeepEqual(expectedKeyValuePairs, page.keyValuePairs) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", expectedKeyValuePairs, page.keyValuePairs)
+	}
+	if !reflect.DeepEqual(expectedChildPageIds, page.childPageIds) {
+		t.Fatalf("Expected child page ids to be %v, received %v", expectedChildPageIds, page.childPageIds)
+	}
+}
+
+func TestDeletesTheMiddleChildrenAlongWithTheirSeparators(t *testing.T) {
+	page := &Page{
+		id:            0,
+		keyValuePairs: []KeyValuePair{{key: []byte("B")}, {key: []byte("C")}, {key: []byte("D")}},
+		level:         1,
+		childPageIds:  []int{10, 11, 12, 13},
+	}
+	page.deleteChildrenRange(1, 3)
+
+	expectedKeyValuePairs := []KeyValuePair{{key: []byte("D")}}
+	expectedChildPageIds := []int{10, 13}
+
+	if !reflect.DeepEqual(expectedKeyValuePairs, page.keyValuePairs) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", expectedKeyValuePairs, page.keyValuePairs)
+	}
+	if !reflect.DeepEqual(expectedChildPageIds, page.childPageIds) {
+		t.Fatalf("Expected child page ids to be %v, received %v", expectedChildPageIds, page.childPageIds)
+	}
+}
+
+func TestMarshalsAPageHeaderWithPageTypeLevelAndEntryCount(t *testing.T) {
+	page := &Page{
+		id:            0,
+		level:         1,
+		keyValuePairs: []KeyValuePair{{key: []byte("A")}},
+		childPageIds:  []int{10, 11},
+	}
+	buffer := page.MarshalBinary()
+	header, _ := UnMarshalPageHeader(buffer)
+
+	if header.PageType != NonLeafPage || header.Level != 1 || header.EntryCount != 1 {
+		t.Fatalf("Expected page header with non-leaf page type, level 1 and 1 entry, received %v", header)
+	}
+	if int(header.FreeSpaceOffset) != int(header.Size())+slotSize {
+		t.Fatalf("Expected free space offset to be %v, received %v", int(header.Size())+slotSize, header.FreeSpaceOffset)
+	}
+}
+
+func TestUnMarshalsAPageWithLevel(t *testing.T) {
+	page := &Page{
+		id:            0,
+		level:         2,
+		keyValuePairs: []KeyValuePair{{key: []byte("A")}},
+		childPageIds:  []int{10, 11},
+	}
+	newPage := &Page{}
+	_ = newPage.UnMarshalBinary(page.MarshalBinary())
+
+	if newPage.level != 2 {
+		t.Fatalf("Expected level to be 2, received %v", newPage.level)
+	}
+}
+
+func TestDoesNotUnMarshalAPageGivenChecksumDoesNotMatch(t *testing.T) {
+	page := &Page{
+		id:            0,
+		keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}},
+	}
+	buffer := page.MarshalBinary()
+	buffer[len(buffer)-1] = 'X'
+
+	err := (&Page{}).UnMarshalBinary(buffer)
+	if err == nil {
+		t.Fatalf("Expected an error while unmarshalling a page with corrupted contents")
+	}
+}
+
+func TestUnMarshalsAnEmptyPageAsAnEmptyLeafPage(t *testing.T) {
+	page := &Page{}
+	err := page.UnMarshalBinary(make([]byte, 100))
+
+	if err != nil || !page.isLeaf() || len(page.keyValuePairs) != 0 {
+		t.Fatalf("Expected an empty leaf page without error, received %v and error %v", page.keyValuePairs, err)
+	}
+}
+
+func TestGetsTheIndexForAKeyBelowTheSharedKeyPrefix(t *testing.T) {
+	page := &Page{
+		keyValuePairs: []KeyValuePair{{key: []byte("tenant/A")}, {key: []byte("tenant/B")}, {key: []byte("tenant/C")}},
+	}
+	index, found := page.Get([]byte("tenan"))
+
+	if index != 0 || found {
+		t.Fatalf("Expected index 0 and key to be missing, received index %v and found %v", index, found)
+	}
+}
+
+func TestGetsTheIndexForAKeyAboveTheSharedKeyPrefix(t *testing.T) {
+	page := &Page{
+		keyValuePairs: []KeyValuePair{{key: []byte("tenant/A")}, {key: []byte("tenant/B")}, {key: []byte("tenant/C")}},
+	}
+	index, found := page.Get([]byte("tenantz"))
+
+	if index != 3 || found {
+		t.Fatalf("Expected index 3 and key to be missing, received index %v and found %v", index, found)
+	}
+}
+
+func TestGetsTheIndexForAKeyWithTheSharedKeyPrefix(t *testing.T) {
+	page := &Page{
+		keyValuePairs: []KeyValuePair{{key: []byte("tenant/A")}, {key: []byte("tenant/B")}, {key: []byte("tenant/C")}},
+	}
+	index, found := page.Get([]byte("tenant/B"))
+
+	if index != 1 || !found {
+		t.Fatalf("Expected index 1 and key to be found, received index %v and found %v", index, found)
+	}
+}
+
+func TestSplitsALeafPageWithTheShortestSeparatorInParent(t *testing.T) {
+	page := &Page{
+		id: 0,
+		keyValuePairs: []KeyValuePair{
+			{key: []byte("https://example.com/docs/a"), value: []byte("Database")},
+			{key: []byte("https://example.com/search?q=b"), value: []byte("Systems")},
+		},
+	}
+	parentPage := NewPage(100)
+	parentPage.childPageIds = []int{0}
+	parentPage.level = 1
+	siblingPage := NewPage(200)
+
+	_, _ = page.split(parentPage, siblingPage, 0, CountBalancedSplit)
+
+	keyValuePairsAfterSplit := parentPage.AllKeyValuePairs()
+	expected := []KeyValuePair{{key: []byte("https://example.com/s")}}
+
+	if !reflect.DeepEqual(expected, keyValuePairsAfterSplit) {
+		t.Fatalf("Expected key value pairs in the parent page after split to be %v, received %v", expected, keyValuePairsAfterSplit)
+	}
+}
+
+func TestReturnsTheShortestSeparatorBetweenTwoKeys(t *testing.T) {
+	separator := shortestSeparator([]byte("user/1234"), []byte("user/1299"))
+
+	if string(separator) != "user/129" {
+		t.Fatalf("Expected separator to be user/129, received %v", string(separator))
+	}
+}
+
+func TestReturnsTheShortestSeparatorWhenTheLeftKeyIsAPrefixOfTheRightKey(t *testing.T) {
+	separator := shortestSeparator([]byte("user"), []byte("user/1299"))
+
+	if string(separator) != "user/" {
+		t.Fatalf("Expected separator to be user/, received %v", string(separator))
+	}
+}
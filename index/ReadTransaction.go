@@ -0,0 +1,192 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrTransactionClosed = errors.New("read transaction is closed")
+
+// ReadTransaction reads the tree without copying keys and values. With a memory mapped index file (or an in-memory
+// tree) the keys and values returned by Get and by the iterators point directly into the memory map. Keys of pages
+// written with KeyPrefixCompression and the pages written with Compression are the exception, such keys are
+// assembled from the prefix and the rest of the key, and such pages are decompressed into a fresh buffer.
+//
+// The returned slices are valid only till the transaction is closed and must not be modified. While a read
+// transaction is open, Put, Update, DeleteRange, Compact and Close wait for it to be closed: pages are written in
+// place and the file may be remapped, either of which would change or invalidate the slices. A goroutine must
+// therefore close its read transaction before modifying the tree. Copy a key or a value to retain it beyond Close.
+type ReadTransaction struct {
+	tree       *BPlusTree
+	rootPageId int
+	closed     bool
+}
+
+// Iterator walks the key value pairs of a ReadTransaction in key order, it is positioned before the first pair.
+type Iterator struct {
+	transaction  *ReadTransaction
+	end          []byte
+	path         []iteratorPosition
+	keyValuePair KeyValuePair
+	err          error
+}
+
+type iteratorPosition struct {
+	view  pageView
+	index int
+}
+
+// BeginReadTransaction opens a read transaction, it must be closed once the returned slices are no longer used.
+func (tree *BPlusTree) BeginReadTransaction() *ReadTransaction {
+	tree.transactionLock.RLock()
+	return &ReadTransaction{tree: tree, rootPageId: tree.pageHierarchy.RootPageId()}
+}
+
+func (transaction *ReadTransaction) Get(key []byte) GetResult {
+	if transaction.closed {
+		return NewFailedGetResult(ErrTransactionClosed)
+	}
+	view, err := transaction.tree.pagePool.View(transaction.rootPageId)
+	for err == nil && !view.isLeaf() {
+		var index, childPageId int
+		if index, err = view.search(key, false); err != nil {
+			break
+		}
+		if childPageId, err = view.childPageIdAt(index); err != nil {
+			break
+		}
+		view, err = transaction.tree.pagePool.View(childPageId)
+	}
+	if err != nil {
+		return NewFailedGetResult(err)
+	}
+	index, err := view.search(key, true)
+	if err != nil {
+		return NewFailedGetResult(err)
+	}
+	if index < view.entryCount() {
+		keyValuePair, err := view.keyValuePairAt(index)
+		if err != nil {
+			return NewFailedGetResult(err)
+		}
+		if bytes.Equal(keyValuePair.key, key) {
+			return NewKeyAvailableGetResult(keyValuePair, index, nil)
+		}
+	}
+	return NewKeyMissingGetResult(index, nil)
+}
+
+// Scan returns an iterator over the keys greater than or equal to start and less than end.
+// A nil start begins at the first key and a nil end runs till the last key.
+func (transaction *ReadTransaction) Scan(start, end []byte) *Iterator {
+	iterator := &Iterator{transaction: transaction, end: end}
+	if transaction.closed {
+		iterator.err = ErrTransactionClosed
+		return iterator
+	}
+	iterator.err = iterator.seek(start)
+	return iterator
+}
+
+func (transaction *ReadTransaction) Close() {
+	if !transaction.closed {
+		transaction.closed = true
+		transaction.tree.transactionLock.RUnlock()
+	}
+}
+
+// Next moves the iterator to the next key value pair and reports whether there is one.
+func (iterator *Iterator) Next() bool {
+	if iterator.err != nil {
+		return false
+	}
+	if iterator.transaction.closed {
+		iterator.err = ErrTransactionClosed
+		return false
+	}
+	for len(iterator.path) > 0 {
+		position := &iterator.path[len(iterator.path)-1]
+		position.index = position.index + 1
+
+		if position.view.isLeaf() {
+			if position.index < position.view.entryCount() {
+				return iterator.moveTo(position.view, position.index)
+			}
+		} else if position.index <= position.view.entryCount() {
+			if iterator.err = iterator.descend(position.view, position.index); iterator.err != nil {
+				return false
+			}
+			continue
+		}
+		iterator.path = iterator.path[:len(iterator.path)-1]
+	}
+	return false
+}
+
+// KeyValuePair returns the key value pair the iterator is positioned at, its key and value follow the lifetime of
+// the ReadTransaction.
+func (iterator *Iterator) KeyValuePair() KeyValuePair {
+	return iterator.keyValuePair
+}
+
+func (iterator *Iterator) Err() error {
+	return iterator.err
+}
+
+func (iterator *Iterator) seek(start []byte) error {
+	view, err := iterator.transaction.tree.pagePool.View(iterator.transaction.rootPageId)
+	if err != nil {
+		return err
+	}
+	for !view.isLeaf() {
+		index := 0
+		if start != nil {
+			if index, err = view.search(start, false); err != nil {
+				return err
+			}
+		}
+		childPageId, err := view.childPageIdAt(index)
+		if err != nil {
+			return err
+		}
+		iterator.path = append(iterator.path, iteratorPosition{view: view, index: index})
+		if view, err = iterator.transaction.tree.pagePool.View(childPageId); err != nil {
+			return err
+		}
+	}
+	index := 0
+	if start != nil {
+		if index, err = view.search(start, true); err != nil {
+			return err
+		}
+	}
+	iterator.path = append(iterator.path, iteratorPosition{view: view, index: index - 1})
+	return nil
+}
+
+func (iterator *Iterator) descend(view pageView, index int) error {
+	childPageId, err := view.childPageIdAt(index)
+	if err != nil {
+		return err
+	}
+	childView, err := iterator.transaction.tree.pagePool.View(childPageId)
+	if err != nil {
+		return err
+	}
+	iterator.path = append(iterator.path, iteratorPosition{view: childView, index: -1})
+	return nil
+}
+
+func (iterator *Iterator) moveTo(view pageView, index int) bool {
+	keyValuePair, err := view.keyValuePairAt(index)
+	if err != nil {
+		iterator.err = err
+		return false
+	}
+	if iterator.end != nil && bytes.Compare(keyValuePair.key, iterator.end) >= 0 {
+		iterator.path = nil
+		return false
+	}
+	iterator.keyValuePair = keyValuePair
+	return true
+}
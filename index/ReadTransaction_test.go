@@ -0,0 +1,133 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func createInMemoryTreeWithKeys(count int) *BPlusTree {
+	options := Options{
+		PageSize:                       os.Getpagesize(),
+		PreAllocatedPagePoolSize:       8,
+		AllowedPageOccupancyPercentage: 20,
+		InMemory:                       true,
+	}
+	tree, _ := CreateBPlusTree(options)
+	for index := 0; index < count; index++ {
+		key := []byte("Key" + strconv.Itoa(index))
+		_ = tree.Put(key, []byte("Value"+strconv.Itoa(index)))
+	}
+	return tree
+}
+
+func TestGetsAValueByKeyInAReadTransaction(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	for index := 0; index < 1000; index = index + 7 {
+		getResult := transaction.Get([]byte("Key" + strconv.Itoa(index)))
+		expected := "Value" + strconv.Itoa(index)
+		if !getResult.found || getResult.KeyValuePair.PrettyValue() != expected {
+			t.Fatalf("Expected value %v, received %v", expected, getResult.KeyValuePair.PrettyValue())
+		}
+	}
+}
+
+func TestDoesNotGetAMissingKeyInAReadTransaction(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	getResult := transaction.Get([]byte("Missing"))
+	if getResult.found || getResult.Err != nil {
+		t.Fatalf("Expected key to be missing, received %v", getResult)
+	}
+}
+
+func TestScansAllTheKeyValuePairsInKeyOrderInAReadTransaction(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	count := 0
+	var previousKey []byte
+	iterator := transaction.Scan(nil, nil)
+	for iterator.Next() {
+		key := iterator.KeyValuePair().key
+		if previousKey != nil && bytes.Compare(previousKey, key) >= 0 {
+			t.Fatalf("Expected keys in order, received %v after %v", string(key), string(previousKey))
+		}
+		previousKey = key
+		count++
+	}
+	if iterator.Err() != nil || count != 1000 {
+		t.Fatalf("Expected 1000 key value pairs, received %v with error %v", count, iterator.Err())
+	}
+}
+
+func TestScansARangeOfKeysInAReadTransaction(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	var keys []string
+	iterator := transaction.Scan([]byte("Key500"), []byte("Key503"))
+	for iterator.Next() {
+		keys = append(keys, iterator.KeyValuePair().PrettyKey())
+	}
+	expected := []string{"Key500", "Key501", "Key502"}
+	if len(keys) != len(expected) || keys[0] != expected[0] || keys[2] != expected[2] {
+		t.Fatalf("Expected keys %v, received %v", expected, keys)
+	}
+}
+
+func TestReturnsAValuePointingIntoTheMemoryMapInAReadTransaction(t *testing.T) {
+	options := DefaultOptions()
+	options.FileName = "./test"
+	tree, _ := CreateBPlusTree(options)
+	defer deleteFile(tree.pagePool.storage)
+
+	_ = tree.Put([]byte("Key"), []byte("Database Systems"))
+	transaction := tree.BeginReadTransaction()
+	defer transaction.Close()
+
+	value := transaction.Get([]byte("Key")).KeyValuePair.value
+	memoryMap := tree.pagePool.storage.(*IndexFile).memoryMap
+	index := bytes.Index(memoryMap, []byte("Database Systems"))
+	if index < 0 || &memoryMap[index] != &value[0] {
+		t.Fatalf("Expected the value to point into the memory map")
+	}
+}
+
+func TestWaitsForTheReadTransactionToCloseBeforePut(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(10)
+	transaction := tree.BeginReadTransaction()
+
+	done := make(chan struct{})
+	go func() {
+		_ = tree.Put([]byte("Key"), []byte("Value"))
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("Expected Put to wait for the read transaction to close")
+	case <-time.After(50 * time.Millisecond):
+	}
+	transaction.Close()
+	<-done
+}
+
+func TestFailsToGetAfterTheReadTransactionIsClosed(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(10)
+	transaction := tree.BeginReadTransaction()
+	transaction.Close()
+
+	getResult := transaction.Get([]byte("Key1"))
+	if getResult.Err != ErrTransactionClosed {
+		t.Fatalf("Expected %v, received %v", ErrTransactionClosed, getResult.Err)
+	}
+}
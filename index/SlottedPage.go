@@ -0,0 +1,316 @@
+package index
+
+import (
+	"b+tree/index/schema"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// A page is laid out as a fixed size header, followed by a slot directory growing towards the end of the page and
+// a cell area growing from the end of the page towards the slots. Each slot holds the offset of one cell, slots are
+// kept in key order while cells are placed wherever there is room. A leaf cell is a key value pair, a non-leaf cell
+// is a key along with the id of the child page to its right as a varint, the first child page id is kept in the
+// header.
+// The free space of a page lies between the FreeSpaceOffset (end of slots) and the CellAreaOffset (start of cells).
+// With key prefix compression, the prefix shared by all the keys is kept once in the cell area at the PrefixOffset
+// and the cells hold only the rest of the keys.
+const slotSize = 4
+
+var pageHeaderSize = int((&schema.PersistentPageHeader{}).Size())
+
+type byteRange struct {
+	from int
+	to   int
+}
+
+func (page Page) cellAt(index int, keyPrefixLength int) persistentPage {
+	if page.isLeaf() {
+		keyValuePair := page.keyValuePairs[index].toPersistentKeyValuePair()
+		keyValuePair.Key = keyValuePair.Key[keyPrefixLength:]
+		return &keyValuePair
+	}
+	cell := &schema.PersistentNonLeafCell{Key: page.keyValuePairs[index].key[keyPrefixLength:]}
+	if index+1 < len(page.childPageIds) {
+		cell.ChildPageId = uint64(page.childPageIds[index+1])
+	}
+	return cell
+}
+
+// marshalInto lays the page out in the buffer with cells packed against the end of the buffer.
+// The buffer must be at least page.size() bytes long.
+func (page *Page) marshalInto(buffer []byte) {
+	header := page.header()
+	cellAreaOffset := len(buffer)
+	freeSpaceOffset := pageHeaderSize
+
+	keyPrefixLength := page.sharedKeyPrefixLength()
+	if keyPrefixLength > 0 {
+		cellAreaOffset = cellAreaOffset - keyPrefixLength
+		copy(buffer[cellAreaOffset:], page.keyValuePairs[0].key[:keyPrefixLength])
+		header.PrefixOffset = uint32(cellAreaOffset)
+		header.PrefixLength = uint32(keyPrefixLength)
+	}
+	for index := range page.keyValuePairs {
+		cell := page.cellAt(index, keyPrefixLength)
+		cellAreaOffset = cellAreaOffset - int(cell.Size())
+		_, _ = cell.Marshal(buffer[cellAreaOffset:])
+		binary.LittleEndian.PutUint32(buffer[freeSpaceOffset:], uint32(cellAreaOffset))
+		freeSpaceOffset = freeSpaceOffset + slotSize
+	}
+	header.FreeSpaceOffset = uint32(freeSpaceOffset)
+	header.CellAreaOffset = uint32(cellAreaOffset)
+	header.Checksum, _ = checksumOf(buffer, header)
+	_, _ = header.Marshal(buffer)
+}
+
+func (page *Page) unMarshalCells(buffer []byte, header *schema.PersistentPageHeader) error {
+	prefix := buffer[header.PrefixOffset : header.PrefixOffset+header.PrefixLength]
+	keyOf := func(suffix []byte) []byte {
+		if len(prefix) == 0 {
+			return suffix
+		}
+		return append(append(make([]byte, 0, len(prefix)+len(suffix)), prefix...), suffix...)
+	}
+	switch header.PageType {
+	case LeafPage:
+		for slot := 0; slot < int(header.EntryCount); slot++ {
+			persistentKeyValuePair := schema.PersistentKeyValuePair{}
+			_, _ = persistentKeyValuePair.Unmarshal(buffer[slotAt(buffer, slot):])
+
+			page.keyValuePairs = append(
+				page.keyValuePairs,
+				KeyValuePair{
+					key: keyOf(persistentKeyValuePair.Key), value: persistentKeyValuePair.Value,
+				},
+			)
+		}
+	case NonLeafPage:
+		if header.EntryCount == 0 && header.FirstChildPageId == metaPageId {
+			return nil
+		}
+		firstChildPageId, err := pageIdOf(header.FirstChildPageId)
+		if err != nil {
+			return fmt.Errorf("page %v: %w", page.id, err)
+		}
+		page.childPageIds = append(page.childPageIds, firstChildPageId)
+		for slot := 0; slot < int(header.EntryCount); slot++ {
+			persistentNonLeafCell := schema.PersistentNonLeafCell{}
+			_, _ = persistentNonLeafCell.Unmarshal(buffer[slotAt(buffer, slot):])
+
+			childPageId, err := pageIdOf(persistentNonLeafCell.ChildPageId)
+			if err != nil {
+				return fmt.Errorf("page %v: %w", page.id, err)
+			}
+			page.keyValuePairs = append(page.keyValuePairs, KeyValuePair{key: keyOf(persistentNonLeafCell.Key)})
+			page.childPageIds = append(page.childPageIds, childPageId)
+		}
+	default:
+		return fmt.Errorf("page %v has an unknown page type %v", page.id, header.PageType)
+	}
+	return nil
+}
+
+func validateLayout(buffer []byte, header *schema.PersistentPageHeader) error {
+	freeSpaceOffset, cellAreaOffset := int(header.FreeSpaceOffset), int(header.CellAreaOffset)
+	if freeSpaceOffset < pageHeaderSize || freeSpaceOffset > cellAreaOffset || cellAreaOffset > len(buffer) {
+		return fmt.Errorf("invalid free space offset %v and cell area offset %v", freeSpaceOffset, cellAreaOffset)
+	}
+	if freeSpaceOffset-pageHeaderSize != int(header.EntryCount)*slotSize {
+		return fmt.Errorf("slot directory of %v bytes does not match %v entries", freeSpaceOffset-pageHeaderSize, header.EntryCount)
+	}
+	if header.PrefixLength > 0 {
+		prefixOffset, prefixLength := int(header.PrefixOffset), int(header.PrefixLength)
+		if prefixOffset < cellAreaOffset || prefixLength > len(buffer) || prefixOffset+prefixLength > len(buffer) {
+			return fmt.Errorf("invalid key prefix offset %v and length %v", prefixOffset, prefixLength)
+		}
+	}
+	return nil
+}
+
+// checksumOf computes the checksum over the slot directory, the key prefix and the cells referred by the slots,
+// so that the unused bytes between and around the cells do not take part.
+func checksumOf(buffer []byte, header *schema.PersistentPageHeader) (uint32, error) {
+	checksum := crc32.ChecksumIEEE(buffer[pageHeaderSize:header.FreeSpaceOffset])
+	checksum = crc32.Update(checksum, crc32.IEEETable, buffer[header.PrefixOffset:header.PrefixOffset+header.PrefixLength])
+	for slot := 0; slot < int(header.EntryCount); slot++ {
+		offset := slotAt(buffer, slot)
+		if offset < int(header.CellAreaOffset) {
+			return 0, fmt.Errorf("slot %v points to offset %v outside the cell area", slot, offset)
+		}
+		length, err := cellLength(buffer, offset, header.PageType)
+		if err != nil {
+			return 0, err
+		}
+		checksum = crc32.Update(checksum, crc32.IEEETable, buffer[offset:offset+length])
+	}
+	return checksum, nil
+}
+
+func cellLength(buffer []byte, offset int, pageType uint8) (int, error) {
+	readLength := func(at int) (int, error) {
+		if at >= len(buffer) {
+			return 0, fmt.Errorf("cell at offset %v overflows the page", offset)
+		}
+		length, lengthSize := binary.Uvarint(buffer[at:])
+		if lengthSize <= 0 || length > uint64(len(buffer)) {
+			return 0, fmt.Errorf("cell at offset %v has an invalid length", offset)
+		}
+		return lengthSize + int(length), nil
+	}
+	length, err := readLength(offset)
+	if err != nil {
+		return 0, err
+	}
+	if pageType == NonLeafPage {
+		if offset+length >= len(buffer) {
+			return 0, fmt.Errorf("cell at offset %v overflows the page", offset)
+		}
+		_, childPageIdSize := binary.Uvarint(buffer[offset+length:])
+		if childPageIdSize <= 0 {
+			return 0, fmt.Errorf("cell at offset %v has an invalid child page id", offset)
+		}
+		length = length + childPageIdSize
+	} else {
+		valueLength, err := readLength(offset + length)
+		if err != nil {
+			return 0, err
+		}
+		length = length + valueLength
+	}
+	if offset+length > len(buffer) {
+		return 0, fmt.Errorf("cell at offset %v overflows the page", offset)
+	}
+	return length, nil
+}
+
+func slotAt(buffer []byte, slot int) int {
+	return int(binary.LittleEndian.Uint32(buffer[pageHeaderSize+slot*slotSize:]))
+}
+
+// insertCellAt writes the cell of the key value pair at the index into the image of a leaf page and shifts the
+// slots after it, leaving the other cells untouched. The image is dropped when it can not take the cell, so that
+// the next write lays the page out afresh.
+func (page *Page) insertCellAt(index int) {
+	header, ok := page.imageHeader()
+	if !ok {
+		return
+	}
+	if page.sharedKeyPrefixLength() != int(header.PrefixLength) {
+		page.dropImage()
+		return
+	}
+	cell := page.cellAt(index, int(header.PrefixLength))
+	cellSize := int(cell.Size())
+	if int(header.CellAreaOffset)-int(header.FreeSpaceOffset) < cellSize+slotSize {
+		page.dropImage()
+		return
+	}
+	cellOffset := int(header.CellAreaOffset) - cellSize
+	_, _ = cell.Marshal(page.image[cellOffset:])
+
+	slotOffset := pageHeaderSize + index*slotSize
+	copy(page.image[slotOffset+slotSize:], page.image[slotOffset:header.FreeSpaceOffset])
+	binary.LittleEndian.PutUint32(page.image[slotOffset:], uint32(cellOffset))
+
+	header.EntryCount = header.EntryCount + 1
+	header.FreeSpaceOffset = header.FreeSpaceOffset + slotSize
+	header.CellAreaOffset = uint32(cellOffset)
+
+	page.markDirty(slotOffset, int(header.FreeSpaceOffset))
+	page.markDirty(cellOffset, cellOffset+cellSize)
+	page.writeImageHeader(header)
+}
+
+// updateCellAt overwrites the cell of the key value pair at the index in the image of a leaf page. A cell that
+// does not grow is rewritten in place, a larger one is placed in the free space and its slot is repointed.
+func (page *Page) updateCellAt(index int) {
+	header, ok := page.imageHeader()
+	if !ok {
+		return
+	}
+	cellOffset := slotAt(page.image, index)
+	existingCellSize, err := cellLength(page.image, cellOffset, LeafPage)
+	if err != nil {
+		page.dropImage()
+		return
+	}
+	cell := page.cellAt(index, int(header.PrefixLength))
+	cellSize := int(cell.Size())
+	if cellSize > existingCellSize {
+		if int(header.CellAreaOffset)-int(header.FreeSpaceOffset) < cellSize {
+			page.dropImage()
+			return
+		}
+		cellOffset = int(header.CellAreaOffset) - cellSize
+		header.CellAreaOffset = uint32(cellOffset)
+
+		slotOffset := pageHeaderSize + index*slotSize
+		binary.LittleEndian.PutUint32(page.image[slotOffset:], uint32(cellOffset))
+		page.markDirty(slotOffset, slotOffset+slotSize)
+	}
+	_, _ = cell.Marshal(page.image[cellOffset:])
+	page.markDirty(cellOffset, cellOffset+cellSize)
+	page.writeImageHeader(header)
+}
+
+// changedRanges returns the byte ranges of the page image that have changed since the page was last written.
+// A page without an image of pageSize bytes is laid out afresh and returned as a whole.
+func (page *Page) changedRanges(pageSize int) ([]byteRange, error) {
+	if len(page.image) != pageSize {
+		if page.size() > pageSize {
+			return nil, fmt.Errorf("page %v of %v bytes does not fit in the page size %v", page.id, page.size(), pageSize)
+		}
+		page.image = make([]byte, pageSize)
+		page.marshalInto(page.image)
+		page.dirtyRanges = nil
+		return []byteRange{{from: 0, to: pageSize}}, nil
+	}
+	header, _ := UnMarshalPageHeader(page.image)
+	header.Lsn = page.lsn
+	_, _ = header.Marshal(page.image)
+	page.markDirty(0, pageHeaderSize)
+
+	dirtyRanges := page.dirtyRanges
+	page.dirtyRanges = nil
+	return dirtyRanges, nil
+}
+
+func (page *Page) imageHeader() (*schema.PersistentPageHeader, bool) {
+	if page.image == nil {
+		return nil, false
+	}
+	header, err := UnMarshalPageHeader(page.image)
+	if err != nil || header.PageType != LeafPage || validateLayout(page.image, header) != nil {
+		page.dropImage()
+		return nil, false
+	}
+	return header, true
+}
+
+func (page *Page) writeImageHeader(header *schema.PersistentPageHeader) {
+	checksum, err := checksumOf(page.image, header)
+	if err != nil {
+		page.dropImage()
+		return
+	}
+	header.Checksum = checksum
+	_, _ = header.Marshal(page.image)
+	page.markDirty(0, pageHeaderSize)
+}
+
+func (page *Page) markDirty(from, to int) {
+	page.dirtyRanges = append(page.dirtyRanges, byteRange{from: from, to: to})
+}
+
+func (page *Page) dropImage() {
+	page.image = nil
+	page.dirtyRanges = nil
+}
+
+// resetLayout is called after a structural change to the page, the page is then laid out afresh on the next write
+// and its size is recomputed on the next size check.
+func (page *Page) resetLayout() {
+	page.dropImage()
+	page.sizeInBytes = 0
+}
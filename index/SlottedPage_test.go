@@ -0,0 +1,164 @@
+package index
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func newMemoryPagePool(pageCount int) *PagePool {
+	options := Options{PageSize: os.Getpagesize(), InMemory: true}
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(pageCount)
+	return pagePool
+}
+
+func TestWritesOnlyTheChangedBytesOfAPageAfterAnInsert(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	page, _ := pagePool.Read(1)
+	page.insertAt(1, KeyValuePair{key: []byte("B"), value: []byte("Storage")})
+	page.lsn = 10
+
+	changedRanges, _ := page.changedRanges(pagePool.pageSize)
+	changedBytes := 0
+	for _, changedRange := range changedRanges {
+		changedBytes = changedBytes + changedRange.to - changedRange.from
+	}
+	if changedBytes >= pagePool.pageSize/2 {
+		t.Fatalf("Expected only a few bytes to change after an insert, received %v changed bytes", changedBytes)
+	}
+}
+
+func TestReadsAPageAfterInsertingIntoItsImage(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	page, _ := pagePool.Read(1)
+	page.insertAt(0, KeyValuePair{key: []byte("0"), value: []byte("Zero")})
+	page.insertAt(2, KeyValuePair{key: []byte("B"), value: []byte("Storage")})
+	_ = pagePool.Write(page)
+
+	readPage, _ := pagePool.Read(1)
+	expected := []KeyValuePair{
+		{key: []byte("0"), value: []byte("Zero")},
+		{key: []byte("A"), value: []byte("Database")},
+		{key: []byte("B"), value: []byte("Storage")},
+	}
+	if !reflect.DeepEqual(expected, readPage.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", expected, readPage.AllKeyValuePairs())
+	}
+}
+
+func TestReadsAPageAfterUpdatingAValueWithALargerValueInItsImage(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("DB")}, {key: []byte("B"), value: []byte("OS")}}})
+
+	page, _ := pagePool.Read(1)
+	page.updateAt(0, KeyValuePair{key: []byte("A"), value: []byte("Database")})
+	page.updateAt(1, KeyValuePair{key: []byte("B"), value: []byte("-")})
+	_ = pagePool.Write(page)
+
+	readPage, _ := pagePool.Read(1)
+	expected := []KeyValuePair{{key: []byte("A"), value: []byte("Database")}, {key: []byte("B"), value: []byte("-")}}
+	if !reflect.DeepEqual(expected, readPage.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", expected, readPage.AllKeyValuePairs())
+	}
+}
+
+func TestLaysOutAPageAfreshWhenItsImageRunsOutOfFreeSpace(t *testing.T) {
+	pagePool := newMemoryPagePool(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}})
+
+	page, _ := pagePool.Read(1)
+	value := make([]byte, pagePool.pageSize/3)
+	for count := 0; count < 3; count++ {
+		page.updateAt(0, KeyValuePair{key: []byte("A"), value: value})
+		value = append(value, 'A')
+	}
+	if page.image != nil {
+		t.Fatalf("Expected the page image to be dropped after running out of free space")
+	}
+	_ = pagePool.Write(page)
+
+	readPage, _ := pagePool.Read(1)
+	if len(readPage.GetKeyValuePairAt(0).value) != pagePool.pageSize/3+2 {
+		t.Fatalf("Expected value of %v bytes, received %v", pagePool.pageSize/3+2, len(readPage.GetKeyValuePairAt(0).value))
+	}
+}
+
+func TestKeepsThePageSizeUpToDateAfterInsertsAndUpdates(t *testing.T) {
+	page := &Page{keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}}
+	_ = page.size()
+
+	page.insertAt(1, KeyValuePair{key: []byte("B"), value: []byte("Storage")})
+	page.updateAt(0, KeyValuePair{key: []byte("A"), value: []byte("DB")})
+
+	expected := (&Page{keyValuePairs: page.keyValuePairs}).size()
+	if expected != page.size() {
+		t.Fatalf("Expected page size to be %v, received %v", expected, page.size())
+	}
+}
+
+func TestFailsToUnMarshalAPageWithASlotOutsideTheCellArea(t *testing.T) {
+	page := &Page{keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Database")}}}
+	buffer := page.MarshalBinary()
+	buffer[pageHeaderSize] = 0
+
+	err := (&Page{}).UnMarshalBinary(buffer)
+	if err == nil {
+		t.Fatalf("Expected an error while unmarshalling a page with a slot outside the cell area")
+	}
+}
+
+func TestMarshalsAPageWithASharedKeyPrefixInFewerBytes(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("tenant/0001/A"), value: []byte("Database")},
+		{key: []byte("tenant/0001/B"), value: []byte("Storage")},
+	}
+	compressedPage := &Page{keyValuePairs: keyValuePairs, compressKeyPrefix: true}
+	page := &Page{keyValuePairs: keyValuePairs}
+
+	if len(compressedPage.MarshalBinary()) >= len(page.MarshalBinary()) {
+		t.Fatalf("Expected the page with key prefix compression to be smaller than %v bytes, received %v bytes", len(page.MarshalBinary()), len(compressedPage.MarshalBinary()))
+	}
+	if len(compressedPage.MarshalBinary()) != compressedPage.size() {
+		t.Fatalf("Expected page size to be %v, received %v", len(compressedPage.MarshalBinary()), compressedPage.size())
+	}
+}
+
+func TestUnMarshalsAPageWithASharedKeyPrefix(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("tenant/0001/A"), value: []byte("Database")},
+		{key: []byte("tenant/0001/B"), value: []byte("Storage")},
+	}
+	page := &Page{keyValuePairs: keyValuePairs, compressKeyPrefix: true}
+
+	readPage := &Page{}
+	_ = readPage.UnMarshalBinary(page.MarshalBinary())
+	if !reflect.DeepEqual(keyValuePairs, readPage.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", keyValuePairs, readPage.AllKeyValuePairs())
+	}
+}
+
+func TestReadsAPageAfterInsertingAKeyThatShortensTheSharedKeyPrefix(t *testing.T) {
+	options := Options{PageSize: os.Getpagesize(), InMemory: true, KeyPrefixCompression: true}
+	pagePool := NewPagePool(NewMemoryStorage(), options)
+	_, _ = pagePool.Allocate(2)
+	_ = pagePool.Write(&Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("tenant/A"), value: []byte("Database")}, {key: []byte("tenant/B"), value: []byte("Storage")}}})
+
+	page, _ := pagePool.Read(1)
+	page.insertAt(2, KeyValuePair{key: []byte("user/C"), value: []byte("Systems")})
+	_ = pagePool.Write(page)
+
+	readPage, _ := pagePool.Read(1)
+	expected := []KeyValuePair{
+		{key: []byte("tenant/A"), value: []byte("Database")},
+		{key: []byte("tenant/B"), value: []byte("Storage")},
+		{key: []byte("user/C"), value: []byte("Systems")},
+	}
+	if !reflect.DeepEqual(expected, readPage.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs to be %v, received %v", expected, readPage.AllKeyValuePairs())
+	}
+}
@@ -0,0 +1,50 @@
+package index
+
+// SplitPolicy decides where a full leaf page is split.
+type SplitPolicy uint8
+
+const (
+	// ByteBalancedSplit splits a leaf page where the serialized bytes of both halves are closest to each other,
+	// so that key value pairs of mixed sizes leave neither half overflowing nor nearly empty
+	ByteBalancedSplit SplitPolicy = iota
+	// CountBalancedSplit splits a leaf page in the middle, leaving both halves with the same number of key value pairs
+	CountBalancedSplit
+
+	// appendSplit moves only the last key value pair to the right sibling, it is used when a key goes past the
+	// rightmost leaf page
+	appendSplit
+)
+
+// splitIndex returns the index of the first key value pair moving to the right sibling. Both halves keep at least one
+// key value pair when the page has two or more.
+func (splitPolicy SplitPolicy) splitIndex(keyValuePairs []KeyValuePair) int {
+	if splitPolicy == CountBalancedSplit || len(keyValuePairs) < 2 {
+		return len(keyValuePairs) / 2
+	}
+	if splitPolicy == appendSplit {
+		return len(keyValuePairs) - 1
+	}
+	sizes := make([]int, len(keyValuePairs))
+	totalSize := 0
+	for index, keyValuePair := range keyValuePairs {
+		persistentKeyValuePair := keyValuePair.toPersistentKeyValuePair()
+		sizes[index] = int(persistentKeyValuePair.Size()) + slotSize
+		totalSize = totalSize + sizes[index]
+	}
+	splitIndex, leftSize := 1, sizes[0]
+	for index := 1; index < len(keyValuePairs)-1; index++ {
+		if abs(2*(leftSize+sizes[index])-totalSize) >= abs(2*leftSize-totalSize) {
+			break
+		}
+		leftSize = leftSize + sizes[index]
+		splitIndex = index + 1
+	}
+	return splitIndex
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+package index
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSplitsKeyValuePairsOfEqualSizesInTheMiddleWithByteBalancedSplit(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: []byte("Database")},
+		{key: []byte("B"), value: []byte("Database")},
+		{key: []byte("C"), value: []byte("Database")},
+		{key: []byte("D"), value: []byte("Database")},
+	}
+	splitIndex := ByteBalancedSplit.splitIndex(keyValuePairs)
+	if splitIndex != 2 {
+		t.Fatalf("Expected split index to be 2, received %v", splitIndex)
+	}
+}
+
+func TestSplitsAfterALargeValueWithByteBalancedSplit(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: bytes.Repeat([]byte("A"), 1000)},
+		{key: []byte("B"), value: []byte("Database")},
+		{key: []byte("C"), value: []byte("Database")},
+		{key: []byte("D"), value: []byte("Database")},
+	}
+	splitIndex := ByteBalancedSplit.splitIndex(keyValuePairs)
+	if splitIndex != 1 {
+		t.Fatalf("Expected split index to be 1, received %v", splitIndex)
+	}
+}
+
+func TestSplitsBeforeALargeValueWithByteBalancedSplit(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: []byte("Database")},
+		{key: []byte("B"), value: []byte("Database")},
+		{key: []byte("C"), value: []byte("Database")},
+		{key: []byte("D"), value: bytes.Repeat([]byte("D"), 1000)},
+	}
+	splitIndex := ByteBalancedSplit.splitIndex(keyValuePairs)
+	if splitIndex != 3 {
+		t.Fatalf("Expected split index to be 3, received %v", splitIndex)
+	}
+}
+
+func TestSplitsInTheMiddleWithCountBalancedSplit(t *testing.T) {
+	keyValuePairs := []KeyValuePair{
+		{key: []byte("A"), value: []byte("Database")},
+		{key: []byte("B"), value: []byte("Database")},
+		{key: []byte("C"), value: []byte("Database")},
+		{key: []byte("D"), value: bytes.Repeat([]byte("D"), 1000)},
+	}
+	splitIndex := CountBalancedSplit.splitIndex(keyValuePairs)
+	if splitIndex != 2 {
+		t.Fatalf("Expected split index to be 2, received %v", splitIndex)
+	}
+}
+
+func TestSplitsALeafPageBalancingTheBytesOfBothHalves(t *testing.T) {
+	largeValue := bytes.Repeat([]byte("A"), 1000)
+	page := &Page{
+		id: 0,
+		keyValuePairs: []KeyValuePair{
+			{key: []byte("A"), value: largeValue},
+			{key: []byte("B"), value: []byte("Database")},
+			{key: []byte("C"), value: []byte("Systems")},
+		},
+	}
+	parentPage := NewPage(100)
+	parentPage.childPageIds = []int{0}
+	parentPage.level = 1
+	siblingPage := NewPage(200)
+
+	_, _ = page.split(parentPage, siblingPage, 0, ByteBalancedSplit)
+
+	expected := []KeyValuePair{{key: []byte("B"), value: []byte("Database")}, {key: []byte("C"), value: []byte("Systems")}}
+	if !reflect.DeepEqual(expected, siblingPage.AllKeyValuePairs()) {
+		t.Fatalf("Expected key value pairs in the sibling page after split to be %v, received %v", expected, siblingPage.AllKeyValuePairs())
+	}
+	if !bytes.Equal([]byte("B"), parentPage.keyValuePairs[0].key) {
+		t.Fatalf("Expected key in the parent page to be B, received %v", string(parentPage.keyValuePairs[0].key))
+	}
+}
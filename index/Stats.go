@@ -0,0 +1,116 @@
+package index
+
+import "fmt"
+
+// Stats describes the shape of the tree and the space it takes, to size the hardware and to tune the PageSize.
+type Stats struct {
+	Height            int
+	LeafPageCount     int
+	InternalPageCount int
+	FreePageCount     int
+	KeyCount          int
+	FileSize          int64
+
+	// AverageOccupancy is the average size of the pages relative to the size at which they are split,
+	// AllowedPageOccupancyPercentage of the PageSize. 1 is a page about to be split
+	AverageOccupancy float64
+
+	// OccupancyHistogram counts the pages by occupancy in steps of 10%, the last bucket counts the pages at or
+	// beyond the split size
+	OccupancyHistogram [occupancyBuckets]int
+
+	KeySizes   SizeDistribution
+	ValueSizes SizeDistribution
+}
+
+// SizeDistribution summarizes sizes in bytes. Histogram[0] counts the empty sizes and Histogram[i] counts the sizes
+// from 2^(i-1) up to 2^i - 1.
+type SizeDistribution struct {
+	Count     int
+	Min       int
+	Max       int
+	Average   float64
+	Histogram []int
+	total     int64
+}
+
+const occupancyBuckets = 11
+
+func (tree BPlusTree) Stats() (Stats, error) {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	stats := Stats{
+		FreePageCount: len(tree.pageHierarchy.freePageList.pageIds),
+		FileSize:      tree.pagePool.storage.Size(),
+	}
+	var totalOccupancy float64
+	splitSize := float64(tree.pageHierarchy.allowedPageOccupancyPercentage*tree.pagePool.pageSize) / 100
+
+	visited := map[int]bool{}
+	var walk func(pageId int, depth int) error
+	walk = func(pageId int, depth int) error {
+		if visited[pageId] {
+			return fmt.Errorf("page %v is reachable more than once, Verify reports the problems of the tree", pageId)
+		}
+		visited[pageId] = true
+		page, err := tree.pageHierarchy.cachedOrReadPage(pageId)
+		if err != nil {
+			return err
+		}
+		size, _ := page.layoutSize()
+		occupancy := float64(size) / splitSize
+		totalOccupancy = totalOccupancy + occupancy
+		bucket := int(occupancy * 10)
+		if bucket >= occupancyBuckets {
+			bucket = occupancyBuckets - 1
+		}
+		stats.OccupancyHistogram[bucket]++
+
+		if !page.isLeaf() {
+			stats.InternalPageCount++
+			for _, childPageId := range page.childPageIds {
+				if err := walk(childPageId, depth+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		stats.LeafPageCount++
+		if depth > stats.Height {
+			stats.Height = depth
+		}
+		for _, keyValuePair := range page.keyValuePairs {
+			stats.KeyCount++
+			stats.KeySizes.add(len(keyValuePair.key))
+			stats.ValueSizes.add(len(keyValuePair.value))
+		}
+		return nil
+	}
+	if err := walk(tree.pageHierarchy.RootPageId(), 1); err != nil {
+		return Stats{}, err
+	}
+	stats.AverageOccupancy = totalOccupancy / float64(stats.LeafPageCount+stats.InternalPageCount)
+	return stats, nil
+}
+
+func (distribution *SizeDistribution) add(size int) {
+	if distribution.Count == 0 || size < distribution.Min {
+		distribution.Min = size
+	}
+	if size > distribution.Max {
+		distribution.Max = size
+	}
+	distribution.Count++
+	distribution.total = distribution.total + int64(size)
+	distribution.Average = float64(distribution.total) / float64(distribution.Count)
+
+	bucket := 0
+	for remaining := size; remaining > 0; remaining = remaining >> 1 {
+		bucket++
+	}
+	for len(distribution.Histogram) <= bucket {
+		distribution.Histogram = append(distribution.Histogram, 0)
+	}
+	distribution.Histogram[bucket]++
+}
@@ -0,0 +1,102 @@
+package index
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReturnsTheStatsOfATree(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+
+	stats, err := tree.Stats()
+	if err != nil {
+		t.Fatalf("Expected no error while returning the stats, received %v", err)
+	}
+	report := tree.Verify()
+	if stats.Height != report.Height || stats.KeyCount != 1000 || stats.LeafPageCount != report.LeafPageCount {
+		t.Fatalf("Expected height %v, 1000 keys and %v leaf pages, received %+v", report.Height, report.LeafPageCount, stats)
+	}
+	if stats.InternalPageCount != report.PageCount-report.LeafPageCount {
+		t.Fatalf("Expected %v internal pages, received %v", report.PageCount-report.LeafPageCount, stats.InternalPageCount)
+	}
+	if stats.FreePageCount != len(tree.pageHierarchy.freePageList.pageIds) || stats.FileSize != tree.pagePool.storage.Size() {
+		t.Fatalf("Expected the free page count and the file size of the tree, received %+v", stats)
+	}
+}
+
+func TestReturnsTheOccupancyOfThePages(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(1000)
+
+	stats, _ := tree.Stats()
+	if stats.AverageOccupancy <= 0.3 || stats.AverageOccupancy > 1.1 {
+		t.Fatalf("Expected average occupancy to be between 0.3 and 1.1, received %v", stats.AverageOccupancy)
+	}
+	pageCount := 0
+	for _, count := range stats.OccupancyHistogram {
+		pageCount = pageCount + count
+	}
+	if pageCount != stats.LeafPageCount+stats.InternalPageCount {
+		t.Fatalf("Expected occupancy histogram to count %v pages, received %v", stats.LeafPageCount+stats.InternalPageCount, pageCount)
+	}
+}
+
+func TestReturnsTheDistributionOfKeyAndValueSizes(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+	_ = tree.Put([]byte("A"), []byte(""))
+	_ = tree.Put([]byte("BB"), []byte("Database"))
+	_ = tree.Put([]byte("CCC"), []byte("Storage"))
+
+	stats, _ := tree.Stats()
+	if stats.KeySizes.Min != 1 || stats.KeySizes.Max != 3 || stats.KeySizes.Average != 2 {
+		t.Fatalf("Expected key sizes from 1 to 3 averaging 2, received %+v", stats.KeySizes)
+	}
+	expectedHistogram := []int{1, 0, 0, 1, 1}
+	for index, expected := range expectedHistogram {
+		if stats.ValueSizes.Histogram[index] != expected {
+			t.Fatalf("Expected value size histogram to be %v, received %v", expectedHistogram, stats.ValueSizes.Histogram)
+		}
+	}
+}
+
+func TestReturnsTheStatsOfAnEmptyTree(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+
+	stats, _ := tree.Stats()
+	if stats.Height != 1 || stats.LeafPageCount != 1 || stats.KeyCount != 0 || stats.KeySizes.Count != 0 {
+		t.Fatalf("Expected an empty tree with a single leaf page, received %+v", stats)
+	}
+}
+
+func TestFailsToReturnTheStatsOfATreeWithAPageReachableMoreThanOnce(t *testing.T) {
+	rootPage := &Page{id: 1, level: 2, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 2}}
+	childPage := &Page{id: 2, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("B")}}, childPageIds: []int{1, 1}}
+	tree := createInMemoryTreeWithPages(rootPage, childPage)
+
+	if _, err := tree.Stats(); err == nil || !strings.Contains(err.Error(), "reachable more than once") {
+		t.Fatalf("Expected an error for the page reachable more than once, received %v", err)
+	}
+}
+
+func TestReturnsTheStatsConcurrentlyWithoutChangingTheCachedRootPage(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("B")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A"), value: []byte("Value")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("B"), value: []byte("Value")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+	rootPage.resetLayout()
+
+	waitGroup := &sync.WaitGroup{}
+	for reader := 0; reader < 4; reader++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if stats, err := tree.Stats(); err != nil || stats.KeyCount != 2 {
+				t.Errorf("Expected 2 keys without an error, received %+v, %v", stats, err)
+			}
+		}()
+	}
+	waitGroup.Wait()
+	if rootPage.sizeInBytes != 0 {
+		t.Fatalf("Expected the size of the cached root page not to be kept by Stats, received %v", rootPage.sizeInBytes)
+	}
+}
@@ -0,0 +1,37 @@
+package index
+
+// Storage is the byte addressable backend behind the PagePool. All the reads and writes are done in pages,
+// the offsets passed to a Storage are always multiples of the page size.
+type Storage interface {
+	ReadPage(offset int64, size int) ([]byte, error)
+	WritePage(offset int64, buffer []byte) error
+	Grow(sizeInBytes int64) error
+	Truncate(sizeInBytes int64) error
+	Sync() error
+	Size() int64
+	Close() error
+}
+
+// PageViewer is implemented by the storages that can hand out a page without copying it. The returned slice refers
+// to the storage itself, it reflects the writes made after it was handed out and is invalid once the storage is
+// grown, truncated or closed.
+type PageViewer interface {
+	ViewPage(offset int64, size int) ([]byte, error)
+}
+
+type StorageType int
+
+const (
+	MemoryMappedStorage StorageType = iota
+	FileIOStorage
+)
+
+func OpenStorage(options Options) (Storage, error) {
+	if options.InMemory {
+		return NewMemoryStorage(), nil
+	}
+	if options.StorageType == FileIOStorage {
+		return OpenFileStorage(options)
+	}
+	return OpenIndexFile(options)
+}
@@ -0,0 +1,151 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// VerifyReport is the outcome of Verify, a tree without Problems is consistent.
+type VerifyReport struct {
+	RootPageId    int
+	Height        int
+	PageCount     int
+	LeafPageCount int
+	KeyCount      int
+	FreePageCount int
+	Problems      []VerifyProblem
+}
+
+// VerifyProblem describes an inconsistency found in a page.
+type VerifyProblem struct {
+	PageId      int
+	Description string
+}
+
+func (report VerifyReport) Ok() bool {
+	return len(report.Problems) == 0
+}
+
+func (report VerifyReport) String() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "root page %v, height %v, %v pages (%v leaf pages), %v keys, %v free pages\n",
+		report.RootPageId, report.Height, report.PageCount, report.LeafPageCount, report.KeyCount, report.FreePageCount)
+	if report.Ok() {
+		builder.WriteString("no problems found\n")
+		return builder.String()
+	}
+	_, _ = fmt.Fprintf(&builder, "%v problems found\n", len(report.Problems))
+	for _, problem := range report.Problems {
+		builder.WriteString(problem.String())
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (problem VerifyProblem) String() string {
+	return fmt.Sprintf("page %v: %v", problem.PageId, problem.Description)
+}
+
+// Verify walks the tree from the root page, through the cached pages and the pages read from the index file, and
+// reports the pages whose keys are not strictly ordered or fall outside the bounds set by the separator keys of their
+// parents, the non-leaf pages whose child page count is not one more than their key count, the leaf pages that are
+// not all at the same depth, the pages reachable more than once and the free pages that are also reachable.
+func (tree BPlusTree) Verify() VerifyReport {
+	tree.transactionLock.RLock()
+	defer tree.transactionLock.RUnlock()
+
+	return tree.pageHierarchy.verify()
+}
+
+type verification struct {
+	pageHierarchy *PageHierarchy
+	report        VerifyReport
+	visited       map[int]bool
+}
+
+func (pageHierarchy *PageHierarchy) verify() VerifyReport {
+	verification := &verification{
+		pageHierarchy: pageHierarchy,
+		report:        VerifyReport{RootPageId: pageHierarchy.RootPageId()},
+		visited:       map[int]bool{},
+	}
+	verification.verifyPage(pageHierarchy.RootPageId(), 1, nil, nil)
+	verification.verifyFreePages()
+	return verification.report
+}
+
+func (verification *verification) verifyPage(pageId int, depth int, lowerKey, upperKey []byte) {
+	if pageId < metaPageCount || pageId >= verification.pageHierarchy.pagePool.pageCount {
+		verification.problem(pageId, "page id is outside the index file of %v pages", verification.pageHierarchy.pagePool.pageCount)
+		return
+	}
+	if verification.visited[pageId] {
+		verification.problem(pageId, "page is reachable more than once")
+		return
+	}
+	verification.visited[pageId] = true
+	verification.report.PageCount++
+
+	page, err := verification.pageHierarchy.cachedOrReadPage(pageId)
+	if err != nil {
+		verification.problem(pageId, "page could not be read: %v", err)
+		return
+	}
+	for index, keyValuePair := range page.keyValuePairs {
+		if index > 0 && bytes.Compare(page.keyValuePairs[index-1].key, keyValuePair.key) >= 0 {
+			verification.problem(pageId, "key %v at %v is not greater than the key %v before it", keyValuePair.PrettyKey(), index, page.keyValuePairs[index-1].PrettyKey())
+		}
+		if lowerKey != nil && bytes.Compare(keyValuePair.key, lowerKey) < 0 {
+			verification.problem(pageId, "key %v at %v is less than the separator key %v of its parent", keyValuePair.PrettyKey(), index, string(lowerKey))
+		}
+		if upperKey != nil && bytes.Compare(keyValuePair.key, upperKey) >= 0 {
+			verification.problem(pageId, "key %v at %v is not less than the separator key %v of its parent", keyValuePair.PrettyKey(), index, string(upperKey))
+		}
+	}
+	if page.isLeaf() {
+		verification.verifyLeafPage(page, depth)
+		return
+	}
+	if len(page.childPageIds) != len(page.keyValuePairs)+1 {
+		verification.problem(pageId, "page has %v child pages for %v keys", len(page.childPageIds), len(page.keyValuePairs))
+	}
+	for index, childPageId := range page.childPageIds {
+		childLowerKey, childUpperKey := lowerKey, upperKey
+		if index > 0 && index-1 < len(page.keyValuePairs) {
+			childLowerKey = page.keyValuePairs[index-1].key
+		}
+		if index < len(page.keyValuePairs) {
+			childUpperKey = page.keyValuePairs[index].key
+		}
+		verification.verifyPage(childPageId, depth+1, childLowerKey, childUpperKey)
+	}
+}
+
+func (verification *verification) verifyLeafPage(page *Page, depth int) {
+	verification.report.LeafPageCount++
+	verification.report.KeyCount = verification.report.KeyCount + len(page.keyValuePairs)
+	if verification.report.Height == 0 {
+		verification.report.Height = depth
+	} else if verification.report.Height != depth {
+		verification.problem(page.id, "leaf page is at depth %v while the other leaf pages are at depth %v", depth, verification.report.Height)
+	}
+}
+
+func (verification *verification) verifyFreePages() {
+	freePageIds := map[int]bool{}
+	for _, pageId := range verification.pageHierarchy.freePageList.pageIds {
+		if freePageIds[pageId] {
+			verification.problem(pageId, "page is listed more than once in the free page list")
+		}
+		if verification.visited[pageId] {
+			verification.problem(pageId, "page is free and reachable from the root page")
+		}
+		freePageIds[pageId] = true
+	}
+	verification.report.FreePageCount = len(freePageIds)
+}
+
+func (verification *verification) problem(pageId int, format string, args ...interface{}) {
+	verification.report.Problems = append(verification.report.Problems, VerifyProblem{PageId: pageId, Description: fmt.Sprintf(format, args...)})
+}
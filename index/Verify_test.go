@@ -0,0 +1,129 @@
+package index
+
+import (
+	"strings"
+	"testing"
+)
+
+func createInMemoryTreeWithPages(rootPage *Page, pages ...*Page) *BPlusTree {
+	tree := createInMemoryTreeWithKeys(0)
+	if len(pages) > 0 {
+		tree.pageHierarchy.freePageList.allocateAndUpdate(len(pages))
+	}
+	for _, page := range append(pages, rootPage) {
+		_ = tree.pagePool.Write(page)
+	}
+	tree.pageHierarchy.rootPage = rootPage
+	tree.pageHierarchy.pageById[rootPage.id] = rootPage
+	return tree
+}
+
+func TestVerifiesATreeWithoutProblems(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(5000)
+	_ = tree.DeleteRange([]byte("Key1"), []byte("Key2"))
+
+	report := tree.Verify()
+	if !report.Ok() {
+		t.Fatalf("Expected no problems, received %v", report)
+	}
+	if report.KeyCount != 3889 {
+		t.Fatalf("Expected key count to be 3889, received %v", report.KeyCount)
+	}
+	if report.Height < 2 || report.LeafPageCount >= report.PageCount {
+		t.Fatalf("Expected a tree with non-leaf pages, received %v", report)
+	}
+}
+
+func TestVerifiesAnEmptyTree(t *testing.T) {
+	tree := createInMemoryTreeWithKeys(0)
+
+	report := tree.Verify()
+	if !report.Ok() || report.Height != 1 || report.KeyCount != 0 {
+		t.Fatalf("Expected an empty tree of height 1 without problems, received %v", report)
+	}
+}
+
+func TestReportsKeysThatAreNotStrictlyOrderedInAPage(t *testing.T) {
+	rootPage := &Page{id: 1, keyValuePairs: []KeyValuePair{{key: []byte("B")}, {key: []byte("A")}, {key: []byte("A")}}}
+	tree := createInMemoryTreeWithPages(rootPage)
+
+	report := tree.Verify()
+	if len(report.Problems) != 2 || !strings.Contains(report.Problems[0].Description, "is not greater than") {
+		t.Fatalf("Expected 2 problems for the keys out of order, received %v", report)
+	}
+}
+
+func TestReportsKeysOutsideTheBoundsOfTheSeparatorKeys(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}, {key: []byte("D")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("B")}, {key: []byte("E")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+
+	report := tree.Verify()
+	if len(report.Problems) != 2 || report.Problems[0].PageId != 2 || report.Problems[1].PageId != 3 {
+		t.Fatalf("Expected problems in page 2 and page 3, received %v", report)
+	}
+}
+
+func TestReportsANonLeafPageWithAMismatchingChildPageCount(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}, {key: []byte("E")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("C")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+
+	report := tree.Verify()
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0].Description, "2 child pages for 2 keys") {
+		t.Fatalf("Expected a problem for the child page count, received %v", report)
+	}
+}
+
+func TestReportsLeafPagesAtDifferentDepths(t *testing.T) {
+	rootPage := &Page{id: 1, level: 2, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}}}
+	rightPage := &Page{id: 3, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("D")}}, childPageIds: []int{4, 5}}
+	rightLeftPage := &Page{id: 4, keyValuePairs: []KeyValuePair{{key: []byte("C")}}}
+	rightRightPage := &Page{id: 5, keyValuePairs: []KeyValuePair{{key: []byte("D")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage, rightLeftPage, rightRightPage)
+
+	report := tree.Verify()
+	if len(report.Problems) != 2 || !strings.Contains(report.Problems[0].Description, "depth 3") {
+		t.Fatalf("Expected problems for the leaf pages at depth 3, received %v", report)
+	}
+}
+
+func TestReportsAPageThatCouldNotBeRead(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("C")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+	_ = tree.pagePool.storage.WritePage(tree.pagePool.offsetOf(3)+int64(tree.pagePool.pageSize)-2, []byte{0xFF, 0xFF})
+
+	report := tree.Verify()
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0].String(), "page 3: page could not be read") {
+		t.Fatalf("Expected a problem for page 3 that could not be read, received %v", report)
+	}
+}
+
+func TestReportsAPageReachableMoreThanOnce(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 2}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage)
+
+	report := tree.Verify()
+	if len(report.Problems) != 1 || report.Problems[0].String() != "page 2: page is reachable more than once" {
+		t.Fatalf("Expected a problem for page 2 reachable more than once, received %v", report)
+	}
+}
+
+func TestReportsAFreePageThatIsReachable(t *testing.T) {
+	rootPage := &Page{id: 1, level: 1, keyValuePairs: []KeyValuePair{{key: []byte("C")}}, childPageIds: []int{2, 3}}
+	leftPage := &Page{id: 2, keyValuePairs: []KeyValuePair{{key: []byte("A")}}}
+	rightPage := &Page{id: 3, keyValuePairs: []KeyValuePair{{key: []byte("C")}}}
+	tree := createInMemoryTreeWithPages(rootPage, leftPage, rightPage)
+	tree.pageHierarchy.freePageList.release([]int{3})
+
+	report := tree.Verify()
+	if len(report.Problems) != 1 || report.Problems[0].String() != "page 3: page is free and reachable from the root page" {
+		t.Fatalf("Expected a problem for the free page 3, received %v", report)
+	}
+}
@@ -12,40 +12,38 @@ var (
 	_ = time.Now()
 )
 
-type PersistentLeafPage struct {
-	PageType byte
-	Pairs    []PersistentKeyValuePair
+type PersistentPageHeader struct {
+	PageType         byte
+	Level            uint16
+	Lsn              uint64
+	EntryCount       uint32
+	Checksum         uint32
+	FreeSpaceOffset  uint32
+	CellAreaOffset   uint32
+	FirstChildPageId uint64
+	PrefixOffset     uint32
+	PrefixLength     uint32
+	Codec            byte
+	CompressedLength uint32
+	Encryption       byte
+	KeyId            uint32
+	Nonce            [12]byte
+	Tag              [16]byte
+	EncryptedLength  uint32
 }
 
-func (d *PersistentLeafPage) Size() (s uint64) {
+func (d *PersistentPageHeader) Size() (s uint64) {
 
 	{
-		l := uint64(len(d.Pairs))
-
-		{
-
-			t := l
-			for t >= 0x80 {
-				t >>= 7
-				s++
-			}
-			s++
-
-		}
-
-		for k0 := range d.Pairs {
-
-			{
-				s += d.Pairs[k0].Size()
-			}
-
-		}
-
+		s += 12
 	}
-	s += 1
+	{
+		s += 16
+	}
+	s += 57
 	return
 }
-func (d *PersistentLeafPage) Marshal(buf []byte) ([]byte, error) {
+func (d *PersistentPageHeader) Marshal(buf []byte) ([]byte, error) {
 	size := d.Size()
 	{
 		if uint64(cap(buf)) >= size {
@@ -60,284 +58,247 @@ func (d *PersistentLeafPage) Marshal(buf []byte) ([]byte, error) {
 		buf[0] = d.PageType
 	}
 	{
-		l := uint64(len(d.Pairs))
 
-		{
+		buf[0+1] = byte(d.Level >> 0)
 
-			t := uint64(l)
+		buf[1+1] = byte(d.Level >> 8)
 
-			for t >= 0x80 {
-				buf[i+1] = byte(t) | 0x80
-				t >>= 7
-				i++
-			}
-			buf[i+1] = byte(t)
-			i++
+	}
+	{
 
-		}
-		for k0 := range d.Pairs {
-
-			{
-				nbuf, err := d.Pairs[k0].Marshal(buf[i+1:])
-				if err != nil {
-					return nil, err
-				}
-				i += uint64(len(nbuf))
-			}
+		buf[0+3] = byte(d.Lsn >> 0)
+
+		buf[1+3] = byte(d.Lsn >> 8)
+
+		buf[2+3] = byte(d.Lsn >> 16)
+
+		buf[3+3] = byte(d.Lsn >> 24)
+
+		buf[4+3] = byte(d.Lsn >> 32)
+
+		buf[5+3] = byte(d.Lsn >> 40)
+
+		buf[6+3] = byte(d.Lsn >> 48)
+
+		buf[7+3] = byte(d.Lsn >> 56)
 
-		}
 	}
-	return buf[:i+1], nil
-}
+	{
 
-func (d *PersistentLeafPage) Unmarshal(buf []byte) (uint64, error) {
-	i := uint64(0)
+		buf[0+11] = byte(d.EntryCount >> 0)
+
+		buf[1+11] = byte(d.EntryCount >> 8)
 
+		buf[2+11] = byte(d.EntryCount >> 16)
+
+		buf[3+11] = byte(d.EntryCount >> 24)
+
+	}
 	{
-		d.PageType = buf[i+0]
+
+		buf[0+15] = byte(d.Checksum >> 0)
+
+		buf[1+15] = byte(d.Checksum >> 8)
+
+		buf[2+15] = byte(d.Checksum >> 16)
+
+		buf[3+15] = byte(d.Checksum >> 24)
+
 	}
 	{
-		l := uint64(0)
 
-		{
+		buf[0+19] = byte(d.FreeSpaceOffset >> 0)
 
-			bs := uint8(7)
-			t := uint64(buf[i+1] & 0x7F)
-			for buf[i+1]&0x80 == 0x80 {
-				i++
-				t |= uint64(buf[i+1]&0x7F) << bs
-				bs += 7
-			}
-			i++
+		buf[1+19] = byte(d.FreeSpaceOffset >> 8)
 
-			l = t
+		buf[2+19] = byte(d.FreeSpaceOffset >> 16)
 
-		}
-		if uint64(cap(d.Pairs)) >= l {
-			d.Pairs = d.Pairs[:l]
-		} else {
-			d.Pairs = make([]PersistentKeyValuePair, l)
-		}
-		for k0 := range d.Pairs {
-
-			{
-				ni, err := d.Pairs[k0].Unmarshal(buf[i+1:])
-				if err != nil {
-					return 0, err
-				}
-				i += ni
-			}
+		buf[3+19] = byte(d.FreeSpaceOffset >> 24)
 
-		}
 	}
-	return i + 1, nil
-}
+	{
 
-type PersistentNonLeafPage struct {
-	PageType     byte
-	Pairs        []PersistentKeyValuePair
-	ChildPageIds []uint32
-}
+		buf[0+23] = byte(d.CellAreaOffset >> 0)
 
-func (d *PersistentNonLeafPage) Size() (s uint64) {
+		buf[1+23] = byte(d.CellAreaOffset >> 8)
 
+		buf[2+23] = byte(d.CellAreaOffset >> 16)
+
+		buf[3+23] = byte(d.CellAreaOffset >> 24)
+
+	}
 	{
-		l := uint64(len(d.Pairs))
 
-		{
+		buf[0+27] = byte(d.FirstChildPageId >> 0)
 
-			t := l
-			for t >= 0x80 {
-				t >>= 7
-				s++
-			}
-			s++
+		buf[1+27] = byte(d.FirstChildPageId >> 8)
 
-		}
+		buf[2+27] = byte(d.FirstChildPageId >> 16)
 
-		for k0 := range d.Pairs {
+		buf[3+27] = byte(d.FirstChildPageId >> 24)
 
-			{
-				s += d.Pairs[k0].Size()
-			}
+		buf[4+27] = byte(d.FirstChildPageId >> 32)
 
-		}
+		buf[5+27] = byte(d.FirstChildPageId >> 40)
+
+		buf[6+27] = byte(d.FirstChildPageId >> 48)
+
+		buf[7+27] = byte(d.FirstChildPageId >> 56)
 
 	}
 	{
-		l := uint64(len(d.ChildPageIds))
 
-		{
+		buf[0+35] = byte(d.PrefixOffset >> 0)
 
-			t := l
-			for t >= 0x80 {
-				t >>= 7
-				s++
-			}
-			s++
+		buf[1+35] = byte(d.PrefixOffset >> 8)
 
-		}
+		buf[2+35] = byte(d.PrefixOffset >> 16)
 
-		s += 4 * l
+		buf[3+35] = byte(d.PrefixOffset >> 24)
 
 	}
-	s += 1
-	return
-}
-func (d *PersistentNonLeafPage) Marshal(buf []byte) ([]byte, error) {
-	size := d.Size()
 	{
-		if uint64(cap(buf)) >= size {
-			buf = buf[:size]
-		} else {
-			buf = make([]byte, size)
-		}
-	}
-	i := uint64(0)
 
+		buf[0+39] = byte(d.PrefixLength >> 0)
+
+		buf[1+39] = byte(d.PrefixLength >> 8)
+
+		buf[2+39] = byte(d.PrefixLength >> 16)
+
+		buf[3+39] = byte(d.PrefixLength >> 24)
+
+	}
 	{
-		buf[0] = d.PageType
+		buf[43] = d.Codec
 	}
 	{
-		l := uint64(len(d.Pairs))
 
-		{
+		buf[0+44] = byte(d.CompressedLength >> 0)
 
-			t := uint64(l)
+		buf[1+44] = byte(d.CompressedLength >> 8)
 
-			for t >= 0x80 {
-				buf[i+1] = byte(t) | 0x80
-				t >>= 7
-				i++
-			}
-			buf[i+1] = byte(t)
-			i++
+		buf[2+44] = byte(d.CompressedLength >> 16)
 
-		}
-		for k0 := range d.Pairs {
-
-			{
-				nbuf, err := d.Pairs[k0].Marshal(buf[i+1:])
-				if err != nil {
-					return nil, err
-				}
-				i += uint64(len(nbuf))
-			}
+		buf[3+44] = byte(d.CompressedLength >> 24)
 
-		}
 	}
 	{
-		l := uint64(len(d.ChildPageIds))
+		buf[48] = d.Encryption
+	}
+	{
 
-		{
+		buf[0+49] = byte(d.KeyId >> 0)
 
-			t := uint64(l)
+		buf[1+49] = byte(d.KeyId >> 8)
 
-			for t >= 0x80 {
-				buf[i+1] = byte(t) | 0x80
-				t >>= 7
-				i++
-			}
-			buf[i+1] = byte(t)
-			i++
-
-		}
-		for k0 := range d.ChildPageIds {
+		buf[2+49] = byte(d.KeyId >> 16)
 
-			{
+		buf[3+49] = byte(d.KeyId >> 24)
 
-				buf[i+0+1] = byte(d.ChildPageIds[k0] >> 0)
+	}
+	{
+		copy(buf[i+53:], d.Nonce[:])
+		i += 12
+	}
+	{
+		copy(buf[i+53:], d.Tag[:])
+		i += 16
+	}
+	{
 
-				buf[i+1+1] = byte(d.ChildPageIds[k0] >> 8)
+		buf[i+0+53] = byte(d.EncryptedLength >> 0)
 
-				buf[i+2+1] = byte(d.ChildPageIds[k0] >> 16)
+		buf[i+1+53] = byte(d.EncryptedLength >> 8)
 
-				buf[i+3+1] = byte(d.ChildPageIds[k0] >> 24)
+		buf[i+2+53] = byte(d.EncryptedLength >> 16)
 
-			}
+		buf[i+3+53] = byte(d.EncryptedLength >> 24)
 
-			i += 4
-
-		}
 	}
-	return buf[:i+1], nil
+	return buf[:i+57], nil
 }
 
-func (d *PersistentNonLeafPage) Unmarshal(buf []byte) (uint64, error) {
+func (d *PersistentPageHeader) Unmarshal(buf []byte) (uint64, error) {
 	i := uint64(0)
 
 	{
 		d.PageType = buf[i+0]
 	}
 	{
-		l := uint64(0)
 
-		{
+		d.Level = 0 | (uint16(buf[i+0+1]) << 0) | (uint16(buf[i+1+1]) << 8)
 
-			bs := uint8(7)
-			t := uint64(buf[i+1] & 0x7F)
-			for buf[i+1]&0x80 == 0x80 {
-				i++
-				t |= uint64(buf[i+1]&0x7F) << bs
-				bs += 7
-			}
-			i++
+	}
+	{
 
-			l = t
+		d.Lsn = 0 | (uint64(buf[i+0+3]) << 0) | (uint64(buf[i+1+3]) << 8) | (uint64(buf[i+2+3]) << 16) | (uint64(buf[i+3+3]) << 24) | (uint64(buf[i+4+3]) << 32) | (uint64(buf[i+5+3]) << 40) | (uint64(buf[i+6+3]) << 48) | (uint64(buf[i+7+3]) << 56)
 
-		}
-		if uint64(cap(d.Pairs)) >= l {
-			d.Pairs = d.Pairs[:l]
-		} else {
-			d.Pairs = make([]PersistentKeyValuePair, l)
-		}
-		for k0 := range d.Pairs {
-
-			{
-				ni, err := d.Pairs[k0].Unmarshal(buf[i+1:])
-				if err != nil {
-					return 0, err
-				}
-				i += ni
-			}
+	}
+	{
+
+		d.EntryCount = 0 | (uint32(buf[i+0+11]) << 0) | (uint32(buf[i+1+11]) << 8) | (uint32(buf[i+2+11]) << 16) | (uint32(buf[i+3+11]) << 24)
 
-		}
 	}
 	{
-		l := uint64(0)
 
-		{
+		d.Checksum = 0 | (uint32(buf[i+0+15]) << 0) | (uint32(buf[i+1+15]) << 8) | (uint32(buf[i+2+15]) << 16) | (uint32(buf[i+3+15]) << 24)
 
-			bs := uint8(7)
-			t := uint64(buf[i+1] & 0x7F)
-			for buf[i+1]&0x80 == 0x80 {
-				i++
-				t |= uint64(buf[i+1]&0x7F) << bs
-				bs += 7
-			}
-			i++
+	}
+	{
 
-			l = t
+		d.FreeSpaceOffset = 0 | (uint32(buf[i+0+19]) << 0) | (uint32(buf[i+1+19]) << 8) | (uint32(buf[i+2+19]) << 16) | (uint32(buf[i+3+19]) << 24)
 
-		}
-		if uint64(cap(d.ChildPageIds)) >= l {
-			d.ChildPageIds = d.ChildPageIds[:l]
-		} else {
-			d.ChildPageIds = make([]uint32, l)
-		}
-		for k0 := range d.ChildPageIds {
+	}
+	{
+
+		d.CellAreaOffset = 0 | (uint32(buf[i+0+23]) << 0) | (uint32(buf[i+1+23]) << 8) | (uint32(buf[i+2+23]) << 16) | (uint32(buf[i+3+23]) << 24)
 
-			{
+	}
+	{
 
-				d.ChildPageIds[k0] = 0 | (uint32(buf[i+0+1]) << 0) | (uint32(buf[i+1+1]) << 8) | (uint32(buf[i+2+1]) << 16) | (uint32(buf[i+3+1]) << 24)
+		d.FirstChildPageId = 0 | (uint64(buf[i+0+27]) << 0) | (uint64(buf[i+1+27]) << 8) | (uint64(buf[i+2+27]) << 16) | (uint64(buf[i+3+27]) << 24) | (uint64(buf[i+4+27]) << 32) | (uint64(buf[i+5+27]) << 40) | (uint64(buf[i+6+27]) << 48) | (uint64(buf[i+7+27]) << 56)
 
-			}
+	}
+	{
 
-			i += 4
+		d.PrefixOffset = 0 | (uint32(buf[i+0+35]) << 0) | (uint32(buf[i+1+35]) << 8) | (uint32(buf[i+2+35]) << 16) | (uint32(buf[i+3+35]) << 24)
+
+	}
+	{
+
+		d.PrefixLength = 0 | (uint32(buf[i+0+39]) << 0) | (uint32(buf[i+1+39]) << 8) | (uint32(buf[i+2+39]) << 16) | (uint32(buf[i+3+39]) << 24)
 
-		}
 	}
-	return i + 1, nil
+	{
+		d.Codec = buf[i+43]
+	}
+	{
+
+		d.CompressedLength = 0 | (uint32(buf[i+0+44]) << 0) | (uint32(buf[i+1+44]) << 8) | (uint32(buf[i+2+44]) << 16) | (uint32(buf[i+3+44]) << 24)
+
+	}
+	{
+		d.Encryption = buf[i+48]
+	}
+	{
+
+		d.KeyId = 0 | (uint32(buf[i+0+49]) << 0) | (uint32(buf[i+1+49]) << 8) | (uint32(buf[i+2+49]) << 16) | (uint32(buf[i+3+49]) << 24)
+
+	}
+	{
+		copy(d.Nonce[:], buf[i+53:])
+		i += 12
+	}
+	{
+		copy(d.Tag[:], buf[i+53:])
+		i += 16
+	}
+	{
+
+		d.EncryptedLength = 0 | (uint32(buf[i+0+53]) << 0) | (uint32(buf[i+1+53]) << 8) | (uint32(buf[i+2+53]) << 16) | (uint32(buf[i+3+53]) << 24)
+
+	}
+	return i + 57, nil
 }
 
 type PersistentKeyValuePair struct {
@@ -486,3 +447,283 @@ func (d *PersistentKeyValuePair) Unmarshal(buf []byte) (uint64, error) {
 	}
 	return i + 0, nil
 }
+
+type PersistentNonLeafCell struct {
+	Key         []byte
+	ChildPageId uint64
+}
+
+func (d *PersistentNonLeafCell) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Key))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+
+		t := d.ChildPageId
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	return
+}
+func (d *PersistentNonLeafCell) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Key))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Key)
+		i += l
+	}
+	{
+
+		t := uint64(d.ChildPageId)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	return buf[:i+0], nil
+}
+
+func (d *PersistentNonLeafCell) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.Key)) >= l {
+			d.Key = d.Key[:l]
+		} else {
+			d.Key = make([]byte, l)
+		}
+		copy(d.Key, buf[i+0:])
+		i += l
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.ChildPageId = t
+
+	}
+	return i + 0, nil
+}
+
+type PersistentMetaPage struct {
+	PageType                       byte
+	PageSize                       uint32
+	AllowedPageOccupancyPercentage uint8
+	RootPageId                     uint64
+	Lsn                            uint64
+	KeyId                          uint32
+	PageCount                      uint64
+}
+
+func (d *PersistentMetaPage) Size() (s uint64) {
+
+	s += 34
+	return
+}
+func (d *PersistentMetaPage) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		buf[0] = d.PageType
+	}
+	{
+
+		buf[0+1] = byte(d.PageSize >> 0)
+
+		buf[1+1] = byte(d.PageSize >> 8)
+
+		buf[2+1] = byte(d.PageSize >> 16)
+
+		buf[3+1] = byte(d.PageSize >> 24)
+
+	}
+	{
+
+		buf[0+5] = byte(d.AllowedPageOccupancyPercentage >> 0)
+
+	}
+	{
+
+		buf[0+6] = byte(d.RootPageId >> 0)
+
+		buf[1+6] = byte(d.RootPageId >> 8)
+
+		buf[2+6] = byte(d.RootPageId >> 16)
+
+		buf[3+6] = byte(d.RootPageId >> 24)
+
+		buf[4+6] = byte(d.RootPageId >> 32)
+
+		buf[5+6] = byte(d.RootPageId >> 40)
+
+		buf[6+6] = byte(d.RootPageId >> 48)
+
+		buf[7+6] = byte(d.RootPageId >> 56)
+
+	}
+	{
+
+		buf[0+14] = byte(d.Lsn >> 0)
+
+		buf[1+14] = byte(d.Lsn >> 8)
+
+		buf[2+14] = byte(d.Lsn >> 16)
+
+		buf[3+14] = byte(d.Lsn >> 24)
+
+		buf[4+14] = byte(d.Lsn >> 32)
+
+		buf[5+14] = byte(d.Lsn >> 40)
+
+		buf[6+14] = byte(d.Lsn >> 48)
+
+		buf[7+14] = byte(d.Lsn >> 56)
+
+	}
+	{
+
+		buf[0+22] = byte(d.KeyId >> 0)
+
+		buf[1+22] = byte(d.KeyId >> 8)
+
+		buf[2+22] = byte(d.KeyId >> 16)
+
+		buf[3+22] = byte(d.KeyId >> 24)
+
+	}
+	{
+
+		buf[0+26] = byte(d.PageCount >> 0)
+
+		buf[1+26] = byte(d.PageCount >> 8)
+
+		buf[2+26] = byte(d.PageCount >> 16)
+
+		buf[3+26] = byte(d.PageCount >> 24)
+
+		buf[4+26] = byte(d.PageCount >> 32)
+
+		buf[5+26] = byte(d.PageCount >> 40)
+
+		buf[6+26] = byte(d.PageCount >> 48)
+
+		buf[7+26] = byte(d.PageCount >> 56)
+
+	}
+	return buf[:i+34], nil
+}
+
+func (d *PersistentMetaPage) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		d.PageType = buf[0]
+	}
+	{
+
+		d.PageSize = 0 | (uint32(buf[0+1]) << 0) | (uint32(buf[1+1]) << 8) | (uint32(buf[2+1]) << 16) | (uint32(buf[3+1]) << 24)
+
+	}
+	{
+
+		d.AllowedPageOccupancyPercentage = 0 | (uint8(buf[0+5]) << 0)
+
+	}
+	{
+
+		d.RootPageId = 0 | (uint64(buf[0+6]) << 0) | (uint64(buf[1+6]) << 8) | (uint64(buf[2+6]) << 16) | (uint64(buf[3+6]) << 24) | (uint64(buf[4+6]) << 32) | (uint64(buf[5+6]) << 40) | (uint64(buf[6+6]) << 48) | (uint64(buf[7+6]) << 56)
+
+	}
+	{
+
+		d.Lsn = 0 | (uint64(buf[0+14]) << 0) | (uint64(buf[1+14]) << 8) | (uint64(buf[2+14]) << 16) | (uint64(buf[3+14]) << 24) | (uint64(buf[4+14]) << 32) | (uint64(buf[5+14]) << 40) | (uint64(buf[6+14]) << 48) | (uint64(buf[7+14]) << 56)
+
+	}
+	{
+
+		d.KeyId = 0 | (uint32(buf[0+22]) << 0) | (uint32(buf[1+22]) << 8) | (uint32(buf[2+22]) << 16) | (uint32(buf[3+22]) << 24)
+
+	}
+	{
+
+		d.PageCount = 0 | (uint64(buf[0+26]) << 0) | (uint64(buf[1+26]) << 8) | (uint64(buf[2+26]) << 16) | (uint64(buf[3+26]) << 24) | (uint64(buf[4+26]) << 32) | (uint64(buf[5+26]) << 40) | (uint64(buf[6+26]) << 48) | (uint64(buf[7+26]) << 56)
+
+	}
+	return i + 34, nil
+}